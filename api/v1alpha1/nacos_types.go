@@ -20,6 +20,7 @@ import (
 	"encoding/json"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
@@ -40,7 +41,11 @@ type NacosSpec struct {
 	NodeSelector   map[string]string       `json:"nodeSelector,omitempty" protobuf:"bytes,7,rep,name=nodeSelector"`
 	LivenessProbe  *v1.Probe               `json:"livenessProbe,omitempty" protobuf:"bytes,10,opt,name=livenessProbe"`
 	ReadinessProbe *v1.Probe               `json:"readinessProbe,omitempty" protobuf:"bytes,11,opt,name=readinessProbe"`
+	// 启动探针，未配置时使用默认探针；liveness/readiness/startup 均逐字段覆盖默认值
+	StartupProbe *v1.Probe `json:"startupProbe,omitempty"`
 	Env            []v1.EnvVar             `json:"env,omitempty" patchStrategy:"merge" patchMergeKey:"name" protobuf:"bytes,7,rep,name=env"`
+	// Pod 就绪持续该时间（秒）后才计为就绪成员
+	MinReadySeconds int32 `json:"minReadySeconds,omitempty"`
 	MysqlInitImage string                  `json:"mysqlInitImage,omitempty"`
 
 	// 自定义配置
@@ -70,6 +75,24 @@ type NacosSpec struct {
     AdminSecretChecksum       string                    `json:"adminSecretChecksum,omitempty"`
     // Identity header source from Secret (preferred)
     IdentitySecretRef         *IdentitySecretRef        `json:"identitySecretRef,omitempty"`
+    // 自动修复：Phase 为 Failed 时重启异常 Pod、重新渲染 StatefulSet
+    Heal HealSpec `json:"heal,omitempty"`
+    // 集群失去多数派后的灾难恢复，从运行中的成员选出存活者重建集群
+    Recovery RecoverySpec `json:"recovery,omitempty"`
+    // 集群模式下修改 spec.image 时从最大序号开始逐个升级成员
+    Upgrade UpgradeSpec `json:"upgrade,omitempty"`
+    // operator 访问开启了 server.ssl 的 Nacos 时使用的 TLS 配置
+    TLS *TLSSpec `json:"tls,omitempty"`
+    // 定期发布读取配置、注册查询实例，检查配置与服务发现是否可用
+    Canary CanarySpec `json:"canary,omitempty"`
+    // 集群模式下的 PodDisruptionBudget，默认按保持 Raft 多数派计算 maxUnavailable
+    PodDisruptionBudget PodDisruptionBudgetSpec `json:"podDisruptionBudget,omitempty"`
+    // operator 创建的 Service 的类型、端口与注解
+    Service ServicesSpec `json:"service,omitempty"`
+    // 通过 Ingress 或 Gateway API 暴露控制台
+    Console ConsoleSpec `json:"console,omitempty"`
+    // IP 协议族，用于所有生成的 Service 与 JVM 的地址偏好
+    Network NetworkSpec `json:"network,omitempty"`
 }
 
 type Certification struct {
@@ -109,6 +132,7 @@ type Storage struct {
     VolumeClaimTemplate  *v1.PersistentVolumeClaim `json:"volumeClaimTemplate,omitempty"`
     EmptyDir             *v1.EmptyDirVolumeSource  `json:"emptyDir,omitempty"`
     HostPath             *v1.HostPathVolumeSource  `json:"hostPath,omitempty"`
+    // 删除 CR 时保留 volumeClaimTemplates 生成的 PVC
     KeepAfterDeletion    bool                      `json:"keepAfterDeletion,omitempty"`
     PersistentVolumeSize string                    `json:"persistentVolumeSize,omitempty"`
 }
@@ -145,6 +169,8 @@ type PGInitSpec struct {
     SchemaVersion  int32                    `json:"schemaVersion,omitempty"`
     // Init policy: IfNotPresent|Always|Never|BumpVersion (default IfNotPresent)
     Policy         string                   `json:"policy,omitempty"`
+    // Drop the operator-initialized schema when the Nacos CR is deleted
+    DropOnDeletion bool                     `json:"dropOnDeletion,omitempty"`
 }
 
 // IdentitySecretRef references a Secret that holds Nacos server identity header
@@ -160,14 +186,182 @@ type AdminCredentialsSecretRef struct {
     Name            string `json:"name,omitempty"`
     UsernameKey     string `json:"usernameKey,omitempty"`
     PasswordHashKey string `json:"passwordHashKey,omitempty"`
+    // Plaintext password the operator logs in with when certification is enabled (default key: password)
+    PasswordKey     string `json:"passwordKey,omitempty"`
+}
+
+// HealSpec 自动修复配置
+type HealSpec struct {
+    // 关闭自动修复
+    Disabled bool `json:"disabled,omitempty"`
+    // 同一对象两次修复之间的最小间隔（秒），默认 300
+    IntervalSeconds int32 `json:"intervalSeconds,omitempty"`
+    // Pod 处于 CrashLoopBackOff 且重启次数达到该值时重建，默认 5
+    CrashLoopThreshold int32 `json:"crashLoopThreshold,omitempty"`
+    // 脑裂时清理少数派成员的 raft 数据（data/protocol）并逐个重启，使其重新加入多数派
+    RaftRecovery bool `json:"raftRecovery,omitempty"`
+}
+
+// RecoverySpec 灾难恢复配置：从运行中的成员选出存活者（优先 Ready、raft term 最大、序号最小），
+// 以它重建单节点集群，再逐个扩容到 spec.replicas。
+// 会丢失其他成员的 raft 数据，需要通过注解 nacos.io/recovery-confirm 确认
+type RecoverySpec struct {
+    Enabled bool `json:"enabled,omitempty"`
+    // 失去多数派持续该时间（秒）后才允许恢复，默认 600
+    QuorumLossSeconds int32 `json:"quorumLossSeconds,omitempty"`
+}
+
+// UpgradeSpec 分批升级配置：通过 StatefulSet rollingUpdate.partition 每次升级一个成员，
+// 成员以新版本重新加入集群后再升级下一个，超时后暂停，通过注解 nacos.io/upgrade-resume 继续
+type UpgradeSpec struct {
+    // 关闭后修改镜像时由 StatefulSet 直接滚动更新所有成员
+    Disabled bool `json:"disabled,omitempty"`
+    // 单个成员重新加入集群的超时时间（秒），默认 600
+    MemberTimeoutSeconds int32 `json:"memberTimeoutSeconds,omitempty"`
+}
+
+// CanarySpec 金丝雀检查配置，使用保留分组 NACOS_OPERATOR_CANARY
+type CanarySpec struct {
+    Enabled bool `json:"enabled,omitempty"`
+    // 两次检查的最小间隔（秒），默认 60
+    IntervalSeconds int32 `json:"intervalSeconds,omitempty"`
+}
+
+// PodDisruptionBudgetSpec 集群模式下 PodDisruptionBudget 的配置，maxUnavailable 与 minAvailable 只能设置一个，
+// 都不设置时 maxUnavailable 为 (replicas-1)/2，驱逐后仍保留多数派
+type PodDisruptionBudgetSpec struct {
+    // 不创建 PodDisruptionBudget，已创建的会被删除
+    Disabled bool `json:"disabled,omitempty"`
+    MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+    MinAvailable *intstr.IntOrString `json:"minAvailable,omitempty"`
+}
+
+// ServicesSpec operator 创建的各个 Service 的覆盖配置
+type ServicesSpec struct {
+    // 客户端访问的 <name>-client，默认 NodePort
+    Client ServiceOverride `json:"client,omitempty"`
+    // 集群模式的 <name>-headless，type 只能为 ClusterIP
+    Headless ServiceOverride `json:"headless,omitempty"`
+    // 单机模式与 CR 同名的 Service，默认 ClusterIP
+    Main ServiceOverride `json:"main,omitempty"`
+}
+
+// ServiceOverride 单个 Service 的覆盖配置，未设置的字段使用默认值
+type ServiceOverride struct {
+    Type v1.ServiceType `json:"type,omitempty"`
+    // 设置后代替 CR 的 annotations，如云厂商负载均衡的注解
+    Annotations map[string]string `json:"annotations,omitempty"`
+    // 按 name 修改默认端口，name 不存在时新增端口
+    Ports []ServicePortSpec `json:"ports,omitempty"`
+    // 只用于 NodePort 与 LoadBalancer
+    ExternalTrafficPolicy v1.ServiceExternalTrafficPolicyType `json:"externalTrafficPolicy,omitempty"`
+    // 只用于 LoadBalancer
+    LoadBalancerSourceRanges []string `json:"loadBalancerSourceRanges,omitempty"`
+    // client 与 headless 默认为 IPv4 单栈
+    IPFamilyPolicy *v1.IPFamilyPolicyType `json:"ipFamilyPolicy,omitempty"`
+    IPFamilies []v1.IPFamily `json:"ipFamilies,omitempty"`
+}
+
+// ServicePortSpec 端口覆盖。默认端口：client 8848、rpc（client Service 为 9848，其余为 7848）、
+// new-rpc 9848；默认关闭的端口：raft 7848（client Service）、cluster-rpc 9849
+type ServicePortSpec struct {
+    Name string `json:"name"`
+    // 新增端口时必填
+    Port int32 `json:"port,omitempty"`
+    // 默认端口为对应的容器端口，新增端口与 port 相同
+    TargetPort int32 `json:"targetPort,omitempty"`
+    // 固定的 nodePort，只用于 NodePort 与 LoadBalancer
+    NodePort int32 `json:"nodePort,omitempty"`
+    // false 时不暴露该端口，默认关闭的端口需要设为 true
+    Enabled *bool `json:"enabled,omitempty"`
+}
+
+// NetworkSpec 集群的 IP 协议族。未配置时 client 与 headless Service 为 IPv4 单栈，
+// 第一个协议族为 IPv6 时 JVM 优先使用 IPv6 地址
+type NetworkSpec struct {
+    // 最多两个且不重复，第一个为主协议族
+    IPFamilies []v1.IPFamily `json:"ipFamilies,omitempty"`
+    // 多个协议族且未设置时为 RequireDualStack
+    IPFamilyPolicy *v1.IPFamilyPolicyType `json:"ipFamilyPolicy,omitempty"`
+}
+
+// ConsoleSpec 控制台的对外访问，后端为 <name>-client 的 client 端口
+type ConsoleSpec struct {
+    Ingress ConsoleIngressSpec `json:"ingress,omitempty"`
+    // 需要集群已安装 Gateway API（gateway.networking.k8s.io/v1）
+    HTTPRoute ConsoleHTTPRouteSpec `json:"httpRoute,omitempty"`
+}
+
+// ConsoleIngressSpec 生成与 CR 同名的 networking.k8s.io/v1 Ingress
+type ConsoleIngressSpec struct {
+    Enabled bool `json:"enabled,omitempty"`
+    // ingressClassName，为空时使用集群默认的 IngressClass
+    ClassName string `json:"className,omitempty"`
+    Host string `json:"host,omitempty"`
+    // 默认为 Nacos 的上下文路径
+    Path string `json:"path,omitempty"`
+    // 设置后为 host 开启 TLS
+    TLSSecretName string `json:"tlsSecretName,omitempty"`
+    Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// ConsoleHTTPRouteSpec 生成与 CR 同名的 HTTPRoute，TLS 在 Gateway 的 listener 上配置
+type ConsoleHTTPRouteSpec struct {
+    Enabled bool `json:"enabled,omitempty"`
+    // 挂载的 Gateway，至少一个
+    ParentRefs []GatewayParentRef `json:"parentRefs,omitempty"`
+    Hostnames []string `json:"hostnames,omitempty"`
+    // 默认为 Nacos 的上下文路径
+    Path string `json:"path,omitempty"`
+    Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// GatewayParentRef HTTPRoute 挂载的 Gateway
+type GatewayParentRef struct {
+    Name string `json:"name"`
+    // 默认与 CR 相同
+    Namespace string `json:"namespace,omitempty"`
+    // Gateway 的 listener 名称
+    SectionName string `json:"sectionName,omitempty"`
+}
+
+// TLSSpec operator 以 https 访问 Nacos 的配置
+type TLSSpec struct {
+    // 校验服务端证书的 CA 所在 Secret，certKey 默认为 ca.crt；不配置时使用系统根证书
+    CASecretRef *TLSSecretRef `json:"caSecretRef,omitempty"`
+    // 双向 TLS 的客户端证书所在 Secret，certKey/keyKey 默认为 tls.crt/tls.key
+    ClientCertSecretRef *TLSSecretRef `json:"clientCertSecretRef,omitempty"`
+    // 校验服务端证书时使用的名称，默认为 Pod IP
+    ServerName string `json:"serverName,omitempty"`
+    // 不校验服务端证书
+    InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+}
+
+// TLSSecretRef 引用 Secret 中的 PEM 证书与私钥
+type TLSSecretRef struct {
+    Name    string `json:"name,omitempty"`
+    CertKey string `json:"certKey,omitempty"`
+    KeyKey  string `json:"keyKey,omitempty"`
 }
 
 // NacosStatus defines the observed state of Nacos
 type NacosStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
-	// 记录实例状态
-    Conditions []NacosCondition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,2,rep,name=conditions"`
+	// 标准状态条件（metav1.Condition），如 Ready、Available、Progressing、Degraded。早期版本中该字段为每个 Pod 一条的实例状态，已移至 status.instances
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+	// 最近一次写入状态时处理的 metadata.generation
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// 记录实例状态，每个 Pod 一条（Type 为 leader/follower），即早期版本的 status.conditions
+	Instances []NacosCondition `json:"instances,omitempty"`
+	// 集群成员拓扑，来自 Nacos 节点列表
+	Members []NacosMember `json:"members,omitempty"`
+	// 未就绪的 Pod 及原因
+	UnreadyPods []UnreadyPod `json:"unreadyPods,omitempty"`
+	// 脑裂时按上报的 leader 分组的成员，分裂持续一分钟后才记录，无脑裂时为空
+	Partitions []RaftPartition `json:"partitions,omitempty"`
+	// 首次检查到成员上报不同 leader 的时间，一致时清空
+	SplitBrainSince metav1.Time `json:"splitBrainSince,omitempty"`
 	// 记录事件
 	Event []Event `json:"event,omitempty" protobuf:"bytes,4,opt,name=event"`
 	// 运行状态，主要根据这个字段用来判断是否正常
@@ -185,6 +379,68 @@ type NacosStatus struct {
     ConfigDigest string `json:"configDigest,omitempty"`
     // VersionDigest captures a short hash of the current spec to detect external updates
     VersionDigest string `json:"versionDigest,omitempty"`
+    // 自动修复记录
+    Heal HealStatus `json:"heal,omitempty"`
+    // 灾难恢复进度
+    Recovery RecoveryStatus `json:"recovery,omitempty"`
+    // 分批升级进度
+    Upgrade UpgradeStatus `json:"upgrade,omitempty"`
+    // 集群缩容进度
+    Scale ScaleStatus `json:"scale,omitempty"`
+    // 最近一次金丝雀检查的结果
+    Canary CanaryStatus `json:"canary,omitempty"`
+}
+
+// UpgradeStatus 分批升级进度
+type UpgradeStatus struct {
+    // Upgrading / Paused，没有进行中的升级时为空
+    Phase string `json:"phase,omitempty"`
+    FromImage string `json:"fromImage,omitempty"`
+    ToImage   string `json:"toImage,omitempty"`
+    // StatefulSet 的 rollingUpdate.partition，序号不小于该值的成员使用新镜像
+    Partition int32 `json:"partition,omitempty"`
+    // 正在升级的成员
+    PodName string `json:"podName,omitempty"`
+    // 当前成员开始升级的时间
+    MemberStartTime metav1.Time `json:"memberStartTime,omitempty"`
+    // 暂停原因
+    Message string `json:"message,omitempty"`
+}
+
+// ScaleStatus 集群缩容进度
+type ScaleStatus struct {
+    // Pending / Draining / Removing，没有进行中的缩容时为空
+    Phase string `json:"phase,omitempty"`
+    // 缩容前后的成员数
+    From int32 `json:"from,omitempty"`
+    To   int32 `json:"to,omitempty"`
+    // 离开集群的成员地址，与集群节点接口返回的 address 相同
+    Members []string `json:"members,omitempty"`
+    // 开始缩容的时间
+    StartTime metav1.Time `json:"startTime,omitempty"`
+}
+
+// UnreadyPod 未计为就绪成员的 Pod
+type UnreadyPod struct {
+    PodName string `json:"podName"`
+    Reason  string `json:"reason,omitempty"`
+}
+
+// CanaryStatus 金丝雀检查结果
+type CanaryStatus struct {
+    LastCheckTime metav1.Time `json:"lastCheckTime,omitempty"`
+    // 执行检查的 Pod
+    PodName string       `json:"podName,omitempty"`
+    Config  CanaryResult `json:"config,omitempty"`
+    Naming  CanaryResult `json:"naming,omitempty"`
+}
+
+// CanaryResult 单项检查的结果
+type CanaryResult struct {
+    Success bool `json:"success"`
+    // 耗时（毫秒）
+    LatencyMilliseconds int64  `json:"latencyMilliseconds,omitempty"`
+    Message             string `json:"message,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -250,6 +506,51 @@ type NacosCondition struct {
 	NodeName string `json:"nodeName,omitempty" protobuf:"bytes,4,opt,name=nodeName"`
 }
 
+// NacosMember 集群成员
+type NacosMember struct {
+	// 节点地址 ip:port
+	Address string `json:"address"`
+	// 对应的 Pod，无法匹配时为空
+	PodName string `json:"podName,omitempty"`
+	// UP / DOWN / SUSPICIOUS
+	State   string `json:"state,omitempty"`
+	Version string `json:"version,omitempty"`
+	// 其他节点访问该节点失败的次数
+	FailAccessCnt  int  `json:"failAccessCnt,omitempty"`
+	ReadyToUpgrade bool `json:"readyToUpgrade,omitempty"`
+	// 每个 Raft group 中的角色
+	RaftGroups []RaftGroupStatus `json:"raftGroups,omitempty"`
+	Abilities  MemberAbilities   `json:"abilities,omitempty"`
+}
+
+// RaftGroupStatus 节点在某个 Raft group 中的状态
+type RaftGroupStatus struct {
+	Name string `json:"name"`
+	// leader / follower，无 leader 时为 unknown
+	Role    string   `json:"role"`
+	Leader  string   `json:"leader,omitempty"`
+	Term    int64    `json:"term,omitempty"`
+	Members []string `json:"members,omitempty"`
+}
+
+// RaftPartition 上报相同 leader 的一组成员
+type RaftPartition struct {
+	Leader string `json:"leader,omitempty"`
+	// 组内成员上报的最大 term
+	Term int64 `json:"term,omitempty"`
+	// 成员地址
+	Members []string `json:"members"`
+	// 成员数超过集群半数
+	Majority bool `json:"majority,omitempty"`
+}
+
+// MemberAbilities 节点支持的能力
+type MemberAbilities struct {
+	SupportRemoteConnection bool `json:"supportRemoteConnection,omitempty"`
+	SupportRemoteMetrics    bool `json:"supportRemoteMetrics,omitempty"`
+	SupportJraft            bool `json:"supportJraft,omitempty"`
+}
+
 // 事件
 type Event struct {
 	Status bool `json:"status"`
@@ -294,3 +595,33 @@ type AdminStatus struct {
     LastSecretResourceVersion string      `json:"lastSecretResourceVersion,omitempty"`
     LastSecretChecksum        string      `json:"lastSecretChecksum,omitempty"`
 }
+
+// HealStatus 自动修复记录
+type HealStatus struct {
+    // 最近的修复操作，按时间排序
+    Actions []HealAction `json:"actions,omitempty"`
+}
+
+// HealAction 一次修复操作
+type HealAction struct {
+    // RestartPod / RecreatePod / RenderStatefulSet
+    Type string `json:"type"`
+    // Pod 或 StatefulSet 名称
+    Target string      `json:"target"`
+    Reason string      `json:"reason,omitempty"`
+    Time   metav1.Time `json:"time"`
+}
+
+// RecoveryStatus 灾难恢复进度
+type RecoveryStatus struct {
+    // QuorumLost / AwaitingConfirmation / Bootstrapping / Growing，未失去多数派时为空
+    Phase string `json:"phase,omitempty"`
+    // 首次检测到失去多数派的时间
+    QuorumLostSince metav1.Time `json:"quorumLostSince,omitempty"`
+    // 用于重建集群的成员，等待确认时为推荐的候选成员
+    Survivor string `json:"survivor,omitempty"`
+    // 等待确认时运行中的成员，按就绪、raft term 从高到低排序，确认注解的值需为其中之一
+    Candidates []string `json:"candidates,omitempty"`
+    // 恢复过程中集群的成员数
+    Replicas int32 `json:"replicas,omitempty"`
+}
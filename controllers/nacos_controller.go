@@ -18,26 +18,29 @@ package controllers
 
 import (
 	"context"
-	"reflect"
-	"time"
 
 	"nacos.io/nacos-operator/pkg/service/operator"
 
 	"github.com/go-logr/logr"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	nacosgroupv1alpha1 "nacos.io/nacos-operator/api/v1alpha1"
-
-	myErrors "nacos.io/nacos-operator/pkg/errors"
 )
 
 // NacosReconciler reconciles a Nacos object
@@ -46,12 +49,34 @@ type NacosReconciler struct {
 	Log            logr.Logger
 	Scheme         *runtime.Scheme
 	OperaterClient *operator.OperatorClient
+	// 每个步骤的失败处理策略，未配置的步骤使用 operator.DefaultStepPolicies
+	StepPolicies map[string]operator.StepPolicy
+	Recorder     record.EventRecorder
 }
 
+// 删除 CR 前执行清理的 finalizer
+const nacosFinalizer = "nacos.io/finalizer"
+
 // +kubebuilder:rbac:groups=nacos.io,resources=nacos,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=nacos.io,resources=nacos/status,verbs=get;update;patch
-// +kubebuilder:rbac:groups="",resources=secrets;configmaps,verbs=get;list;watch
-type reconcileFun func(nacos *nacosgroupv1alpha1.Nacos)
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=configmaps;services,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=httproutes,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;update;patch;delete
+// +kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=get
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch;delete
+// +kubebuilder:rbac:groups="",resources=pods/exec,verbs=create
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+type reconcileFun func(nacos *nacosgroupv1alpha1.Nacos) operator.StepResult
+
+type reconcileStep struct {
+	name string
+	fun  reconcileFun
+}
 
 func (r *NacosReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	_ = context.Background()
@@ -66,49 +91,78 @@ func (r *NacosReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 		return reconcile.Result{}, err
 	}
 
-	// 工作逻辑入口 , 引发了painc，返回默认false，重新插入队列,5秒继续执行
-	result := r.ReconcileWork(instance)
-	if result == false {
-		return reconcile.Result{RequeueAfter: time.Second * 5}, nil
-	} else {
-		return reconcile.Result{}, nil
+	// 删除流程
+	if !instance.DeletionTimestamp.IsZero() {
+		return r.finalize(ctx, instance)
+	}
+	if !controllerutil.ContainsFinalizer(instance, nacosFinalizer) {
+		controllerutil.AddFinalizer(instance, nacosFinalizer)
+		if err := r.Client.Update(ctx, instance); err != nil {
+			return reconcile.Result{}, err
+		}
 	}
 
+	// 工作逻辑入口
+	return r.ReconcileWork(instance)
 }
 
-func (r *NacosReconciler) ReconcileWork(instance *nacosgroupv1alpha1.Nacos) bool {
-	// 处理全局异常处理中的异常
-	defer func() {
-		if err := recover(); err != nil {
-			r.Log.Error(err.(error), "unknow error")
-		}
-	}()
+// finalize 清理完成后记录 Event 并移除 finalizer，使 CR 能够被删除
+func (r *NacosReconciler) finalize(ctx context.Context, instance *nacosgroupv1alpha1.Nacos) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(instance, nacosFinalizer) {
+		return reconcile.Result{}, nil
+	}
+	message, result := r.OperaterClient.Finalize(instance)
+	switch result.Action {
+	case operator.ActionContinue:
+	case operator.ActionRequeue:
+		return reconcile.Result{Requeue: true, RequeueAfter: result.RequeueAfter}, nil
+	default:
+		r.Log.Error(result.Err, "finalize nacos failed", "nacos", instance.Name)
+		r.Recorder.Event(instance, corev1.EventTypeWarning, operator.ReasonFinalizeFailed, result.Err.Error())
+		return reconcile.Result{}, result.Err
+	}
 
-	// 全局处理异常
-	defer func() {
-		if err := recover(); err != nil {
-			// 可处理的异常
-			r.globalExceptHandle(err, instance)
-		}
-	}()
+	r.Recorder.Event(instance, corev1.EventTypeNormal, operator.ReasonFinalized, message)
+	controllerutil.RemoveFinalizer(instance, nacosFinalizer)
+	if err := r.Client.Update(ctx, instance); err != nil {
+		return reconcile.Result{}, err
+	}
+	r.Log.Info("nacos finalized", "nacos", instance.Name, "result", message)
+	return reconcile.Result{}, nil
+}
 
-	for _, fun := range []reconcileFun{
-		r.OperaterClient.PreCheck,
+func (r *NacosReconciler) ReconcileWork(instance *nacosgroupv1alpha1.Nacos) (ctrl.Result, error) {
+	for _, step := range []reconcileStep{
+		{operator.StepPreCheck, r.OperaterClient.PreCheck},
 		// PG 连接检查与初始化（前置于资源确保）
-		r.OperaterClient.PGEnsure,
+		{operator.StepPGEnsure, r.OperaterClient.PGEnsure},
 		// 管理员口令旋转（直连 PG）
-		r.OperaterClient.RotateAdmin,
+		{operator.StepRotateAdmin, r.OperaterClient.RotateAdmin},
+		// 失去多数派后的灾难恢复，先于资源确保以按恢复进度渲染 StatefulSet
+		{operator.StepRecover, r.OperaterClient.Recover},
+		// 镜像变化时逐个升级成员，先于资源确保以按升级进度渲染 StatefulSet 的 partition
+		{operator.StepUpgrade, r.OperaterClient.Upgrade},
+		// 缩容时先让离开的成员退出集群，再减少副本数
+		{operator.StepScaleDown, r.OperaterClient.ScaleDown},
 		// 保证资源能够创建
-		r.OperaterClient.MakeEnsure,
+		{operator.StepMakeEnsure, r.OperaterClient.MakeEnsure},
 		// 检查并保障
-		r.OperaterClient.CheckAndMakeHeal,
+		{operator.StepCheckAndMakeHeal, r.OperaterClient.CheckAndMakeHeal},
 		// 保存状态
-		r.OperaterClient.UpdateStatus,
+		{operator.StepUpdateStatus, r.OperaterClient.UpdateStatus},
 	} {
-		fun(instance)
+		result := step.fun(instance)
+		switch result.Action {
+		case operator.ActionContinue:
+			continue
+		case operator.ActionRequeue:
+			return reconcile.Result{Requeue: true, RequeueAfter: result.RequeueAfter}, nil
+		default:
+			return r.handleStepError(step.name, instance, result)
+		}
 	}
 
-	return true
+	return reconcile.Result{}, nil
 }
 
 func filterByLabel(label map[string]string) bool {
@@ -120,62 +174,143 @@ func filterByLabel(label map[string]string) bool {
 	}
 }
 
+// 字段索引：Nacos CR 引用的 Secret / ConfigMap 名称，用于在 Secret / ConfigMap 变更时查找需要调谐的 CR
+const (
+	secretRefIndexKey    = ".spec.secretRefs"
+	configMapRefIndexKey = ".spec.configMapRefs"
+)
+
 func (r *NacosReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	indexer := mgr.GetFieldIndexer()
+	if err := indexer.IndexField(context.Background(), &nacosgroupv1alpha1.Nacos{}, secretRefIndexKey, func(obj client.Object) []string {
+		return secretRefNames(obj.(*nacosgroupv1alpha1.Nacos))
+	}); err != nil {
+		return err
+	}
+	if err := indexer.IndexField(context.Background(), &nacosgroupv1alpha1.Nacos{}, configMapRefIndexKey, func(obj client.Object) []string {
+		return configMapRefNames(obj.(*nacosgroupv1alpha1.Nacos))
+	}); err != nil {
+		return err
+	}
+
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(&nacosgroupv1alpha1.Nacos{}).
 		Owns(&appsv1.StatefulSet{}).
+		Owns(&corev1.Service{}).
+		Owns(&corev1.ConfigMap{}).
+		Owns(&batchv1.Job{}).
+		Owns(&policyv1.PodDisruptionBudget{}).
+		Owns(&networkingv1.Ingress{}).
 		Watches(&source.Kind{Type: &corev1.ConfigMap{}}, handler.EnqueueRequestsFromMapFunc(r.findNacosForConfigMap)).
-		Complete(r)
+		Watches(&source.Kind{Type: &corev1.Secret{}}, handler.EnqueueRequestsFromMapFunc(r.findNacosForSecret))
+
+	// The console HTTPRoute is only watched when the Gateway API CRDs exist at startup;
+	// if they are installed later, route drift is repaired on the next reconcile until the operator restarts
+	gvk := operator.HTTPRouteGVK
+	if _, err := mgr.GetRESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version); err == nil {
+		route := &unstructured.Unstructured{}
+		route.SetGroupVersionKind(gvk)
+		b = b.Owns(route)
+	} else if !meta.IsNoMatchError(err) {
+		return err
+	}
+	return b.Complete(r)
+}
+
+// secretRefNames returns every Secret referenced by the Nacos spec
+func secretRefNames(nacos *nacosgroupv1alpha1.Nacos) []string {
+	var names []string
+	if name := nacos.Spec.Postgres.CredentialsSecretRef.Name; name != "" {
+		names = append(names, name)
+	}
+	if name := nacos.Spec.AdminCredentialsSecretRef.Name; name != "" {
+		names = append(names, name)
+	}
+	if nacos.Spec.IdentitySecretRef != nil && nacos.Spec.IdentitySecretRef.Name != "" {
+		names = append(names, nacos.Spec.IdentitySecretRef.Name)
+	}
+	if tls := nacos.Spec.TLS; tls != nil {
+		if tls.CASecretRef != nil && tls.CASecretRef.Name != "" {
+			names = append(names, tls.CASecretRef.Name)
+		}
+		if tls.ClientCertSecretRef != nil && tls.ClientCertSecretRef.Name != "" {
+			names = append(names, tls.ClientCertSecretRef.Name)
+		}
+	}
+	return names
+}
+
+// configMapRefNames returns every ConfigMap referenced by the Nacos spec
+func configMapRefNames(nacos *nacosgroupv1alpha1.Nacos) []string {
+	var names []string
+	if nacos.Spec.UserConfigRef != nil && nacos.Spec.UserConfigRef.Name != "" {
+		names = append(names, nacos.Spec.UserConfigRef.Name)
+	}
+	if nacos.Spec.InternalConfigRef != nil && nacos.Spec.InternalConfigRef.Name != "" {
+		names = append(names, nacos.Spec.InternalConfigRef.Name)
+	}
+	return names
 }
 
 // findNacosForConfigMap finds Nacos CRs that reference the given ConfigMap
 func (r *NacosReconciler) findNacosForConfigMap(obj client.Object) []reconcile.Request {
-	cm := obj.(*corev1.ConfigMap)
+	return r.findNacosByIndex(configMapRefIndexKey, obj)
+}
 
-	// List all Nacos CRs in the same namespace
+// findNacosForSecret finds Nacos CRs that reference the given Secret
+func (r *NacosReconciler) findNacosForSecret(obj client.Object) []reconcile.Request {
+	return r.findNacosByIndex(secretRefIndexKey, obj)
+}
+
+// findNacosByIndex looks up Nacos CRs in the object's namespace through the given field index
+func (r *NacosReconciler) findNacosByIndex(indexKey string, obj client.Object) []reconcile.Request {
 	nacosList := &nacosgroupv1alpha1.NacosList{}
-	if err := r.Client.List(context.Background(), nacosList, client.InNamespace(cm.Namespace)); err != nil {
-		r.Log.Error(err, "Failed to list Nacos CRs")
+	if err := r.Client.List(context.Background(), nacosList,
+		client.InNamespace(obj.GetNamespace()),
+		client.MatchingFields{indexKey: obj.GetName()}); err != nil {
+		r.Log.Error(err, "Failed to list Nacos CRs", "index", indexKey)
 		return nil
 	}
 
 	var requests []reconcile.Request
 	for _, nacos := range nacosList.Items {
-		// Check if this ConfigMap is referenced by the Nacos CR
-		if (nacos.Spec.UserConfigRef != nil && nacos.Spec.UserConfigRef.Name == cm.Name) ||
-			(nacos.Spec.InternalConfigRef != nil && nacos.Spec.InternalConfigRef.Name == cm.Name) {
-			requests = append(requests, reconcile.Request{
-				NamespacedName: types.NamespacedName{
-					Name:      nacos.Name,
-					Namespace: nacos.Namespace,
-				},
-			})
-			r.Log.Info("ConfigMap change detected, triggering reconcile",
-				"configmap", cm.Name, "nacos", nacos.Name)
-		}
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Name:      nacos.Name,
+				Namespace: nacos.Namespace,
+			},
+		})
+		r.Log.Info("Referenced object change detected, triggering reconcile",
+			"index", indexKey, "object", obj.GetName(), "nacos", nacos.Name)
 	}
 
 	return requests
 }
 
-// 全局异常处理
-func (r *NacosReconciler) globalExceptHandle(err interface{}, instance *nacosgroupv1alpha1.Nacos) {
-	if reflect.TypeOf(err) == reflect.TypeOf(myErrors.NewErrMsg("")) {
-		myerr := err.(*myErrors.Err)
-		r.Log.V(0).Info("painc", "code", myerr.Code, "msg", myerr.Msg)
-		switch myerr.Code {
-		case myErrors.CODE_NORMAL:
-			r.OperaterClient.StatusClient.UpdateStatus(instance)
-			return
-		}
+// 步骤失败处理：宽限期外记录异常状态，并根据策略决定重新入队方式
+func (r *NacosReconciler) handleStepError(step string, instance *nacosgroupv1alpha1.Nacos, result operator.StepResult) (ctrl.Result, error) {
+	policy := r.stepPolicy(step)
+	r.Log.V(0).Info("reconcile step failed", "step", step, "code", result.Err.Code, "msg", result.Err.Msg)
 
-		// 超时3分钟如果还未成功就显示异常
-		if instance.Status.Phase != nacosgroupv1alpha1.PhaseCreating ||
-			instance.CreationTimestamp.Add(time.Minute*3).Before(time.Now()) {
-			r.OperaterClient.StatusClient.UpdateExceptionStatus(instance, myerr)
+	// 创建阶段在宽限期内只重试，不显示异常
+	if !policy.InGracePeriod(instance) {
+		if err := r.OperaterClient.StatusClient.UpdateExceptionStatus(instance, result.Err); err != nil {
+			r.Log.Error(err, "update exception status failed", "step", step)
 		}
-	} else {
-		// 未知的错误，把堆栈打印出来
-		r.Log.Error(err.(error), "unknow error")
 	}
+
+	if result.Action == operator.ActionTerminal {
+		return reconcile.Result{}, nil
+	}
+	if policy.RequeueAfter > 0 {
+		return reconcile.Result{RequeueAfter: policy.RequeueAfter}, nil
+	}
+	return reconcile.Result{}, result.Err
+}
+
+func (r *NacosReconciler) stepPolicy(step string) operator.StepPolicy {
+	if policy, ok := r.StepPolicies[step]; ok {
+		return policy
+	}
+	return operator.DefaultStepPolicies(operator.DefaultCreatingGracePeriod)[step]
 }
@@ -0,0 +1,35 @@
+package controllers
+
+import (
+	"reflect"
+	"testing"
+
+	nacosgroupv1alpha1 "nacos.io/nacos-operator/api/v1alpha1"
+)
+
+func TestRefIndexNames(t *testing.T) {
+	nacos := &nacosgroupv1alpha1.Nacos{
+		Spec: nacosgroupv1alpha1.NacosSpec{
+			Postgres: nacosgroupv1alpha1.NacosPostgresSpec{
+				CredentialsSecretRef: nacosgroupv1alpha1.PGCredentialsSecretRef{Name: "pg-cred"},
+			},
+			AdminCredentialsSecretRef: nacosgroupv1alpha1.AdminCredentialsSecretRef{Name: "admin-cred"},
+			IdentitySecretRef:         &nacosgroupv1alpha1.IdentitySecretRef{Name: "identity"},
+			UserConfigRef:             &nacosgroupv1alpha1.ConfigMapRef{Name: "user-config"},
+			TLS: &nacosgroupv1alpha1.TLSSpec{
+				CASecretRef:         &nacosgroupv1alpha1.TLSSecretRef{Name: "nacos-ca"},
+				ClientCertSecretRef: &nacosgroupv1alpha1.TLSSecretRef{Name: "nacos-client"},
+			},
+		},
+	}
+
+	if got, want := secretRefNames(nacos), []string{"pg-cred", "admin-cred", "identity", "nacos-ca", "nacos-client"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("secretRefNames() = %v, want %v", got, want)
+	}
+	if got, want := configMapRefNames(nacos), []string{"user-config"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("configMapRefNames() = %v, want %v", got, want)
+	}
+	if got := secretRefNames(&nacosgroupv1alpha1.Nacos{}); len(got) != 0 {
+		t.Errorf("secretRefNames() of empty spec = %v, want none", got)
+	}
+}
@@ -19,6 +19,7 @@ package main
 import (
     "flag"
     "os"
+    "time"
 
 	"nacos.io/nacos-operator/pkg/service/operator"
 
@@ -58,10 +59,13 @@ func main() {
 	}()
     var metricsAddr string
     var enableLeaderElection bool
+    var creatingGracePeriod time.Duration
     flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "The address the metric endpoint binds to.")
     flag.BoolVar(&enableLeaderElection, "enable-leader-election", false,
             "Enable leader election for controller manager. "+
                 "Enabling this will ensure there is only one active controller manager.")
+    flag.DurationVar(&creatingGracePeriod, "creating-grace-period", operator.DefaultCreatingGracePeriod,
+            "How long a Creating Nacos may keep failing reconcile steps before it is marked Failed.")
     flag.Parse()
 
 	//ctrl.SetLogger(zap.New(zap.UseDevMode(true)))
@@ -88,11 +92,14 @@ func main() {
 	}
 	log := ctrl.Log.WithName("controllers").WithName("Nacos")
 	clientset, _ := kubernetes.NewForConfig(ctrl.GetConfigOrDie())
+	recorder := mgr.GetEventRecorderFor("nacos-operator")
 	if err = (&controllers.NacosReconciler{
 		Client:         mgr.GetClient(),
 		Log:            log,
 		Scheme:         mgr.GetScheme(),
-		OperaterClient: operator.NewOperatorClient(log, clientset, mgr.GetScheme(), mgr.GetClient()),
+		OperaterClient: operator.NewOperatorClient(log, clientset, mgr.GetConfig(), mgr.GetScheme(), mgr.GetClient(), recorder),
+		StepPolicies:   operator.DefaultStepPolicies(creatingGracePeriod),
+		Recorder:       recorder,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Nacos")
 		os.Exit(1)
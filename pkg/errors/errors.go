@@ -15,31 +15,6 @@ func (e *Err) Error() string {
 	return string(err)
 }
 
-func EnsureNormal(err error) {
-	if err != nil {
-		panic(err)
-	}
-}
-
-func EnsureNormalMyError(err error, code int) {
-    if err != nil {
-        // Use a constant format string to satisfy vet printf checks.
-        panic(New(code, "%v", err))
-    }
-}
-
-func EnsureEqual(i1 interface{}, i2 interface{}, code int, msg ...interface{}) {
-	if i1 != i2 {
-		panic(New(code, "msg: %v, %v is not equal %v", msg, i1, i2))
-	}
-}
-
-func EnsureNormalMsgf(err error, format string, a ...interface{}) {
-	if err != nil {
-		panic(err)
-	}
-}
-
 func New(code int, format string, a ...interface{}) *Err {
 	msg := fmt.Sprintf(format, a...)
 	if len(a) == 0 {
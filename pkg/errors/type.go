@@ -8,6 +8,8 @@ const CODE_PARAMETER_ERROR = 301
 
 // 组件层面错误 4XXX
 const CODE_CLUSTER_FAILE = 401
+const CODE_SPLIT_BRAIN = 402
+const CODE_QUORUM_LOST = 403
 const CODE_ERR_SYSTEM = 404
 
 const CODE_ERR_UNKNOW = -1
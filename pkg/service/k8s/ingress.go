@@ -0,0 +1,63 @@
+package k8s
+
+import (
+	"context"
+	log "github.com/go-logr/logr"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog"
+)
+
+type Ingress interface {
+	GetIngress(namespace string, name string) (*networkingv1.Ingress, error)
+	CreateIngress(namespace string, ingress *networkingv1.Ingress) error
+	UpdateIngress(namespace string, ingress *networkingv1.Ingress) error
+	// DeleteIngress 不存在时不返回错误
+	DeleteIngress(namespace string, name string) error
+}
+
+type IngressService struct {
+	kubeClient kubernetes.Interface
+	logger     log.Logger
+}
+
+func NewIngressService(kubeClient kubernetes.Interface, logger log.Logger) *IngressService {
+	logger = logger.WithValues("service", "k8s.ingress")
+	return &IngressService{
+		kubeClient: kubeClient,
+		logger:     logger,
+	}
+}
+
+func (s *IngressService) GetIngress(namespace string, name string) (*networkingv1.Ingress, error) {
+	ingress, err := s.kubeClient.NetworkingV1().Ingresses(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return ingress, err
+}
+
+func (s *IngressService) CreateIngress(namespace string, ingress *networkingv1.Ingress) error {
+	_, err := s.kubeClient.NetworkingV1().Ingresses(namespace).Create(context.TODO(), ingress, metav1.CreateOptions{})
+	if err != nil {
+		return err
+	}
+	klog.V(2).Infof("create ingress,namespace: %s  name: %s", namespace, ingress.Name)
+	return nil
+}
+
+func (s *IngressService) UpdateIngress(namespace string, ingress *networkingv1.Ingress) error {
+	_, err := s.kubeClient.NetworkingV1().Ingresses(namespace).Update(context.TODO(), ingress, metav1.UpdateOptions{})
+	return err
+}
+
+func (s *IngressService) DeleteIngress(namespace string, name string) error {
+	err := s.kubeClient.NetworkingV1().Ingresses(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package k8s
 import (
 	log "github.com/go-logr/logr"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 )
 
 // Service is the K8s service entrypoint.
@@ -11,6 +12,10 @@ type Services interface {
 	StatefulSet
 	Service
 	Job
+	PersistentVolumeClaim
+	Pod
+	PodDisruptionBudget
+	Ingress
 }
 
 type services struct {
@@ -18,14 +23,22 @@ type services struct {
 	StatefulSet
 	Service
 	Job
+	PersistentVolumeClaim
+	Pod
+	PodDisruptionBudget
+	Ingress
 }
 
-// New returns a new Kubernetes service.
-func NewK8sService(kubecli kubernetes.Interface, logger log.Logger) Services {
+// New returns a new Kubernetes service. restConfig is only used to exec into pods and may be nil.
+func NewK8sService(kubecli kubernetes.Interface, restConfig *rest.Config, logger log.Logger) Services {
 	return &services{
-		ConfigMap:   NewConfigMapService(kubecli, logger),
-		StatefulSet: NewStatefulSetService(kubecli, logger),
-		Service:     NewServiceService(kubecli, logger),
-		Job:         NewJobService(kubecli, logger),
+		ConfigMap:             NewConfigMapService(kubecli, logger),
+		StatefulSet:           NewStatefulSetService(kubecli, logger),
+		Service:               NewServiceService(kubecli, logger),
+		Job:                   NewJobService(kubecli, logger),
+		PersistentVolumeClaim: NewPersistentVolumeClaimService(kubecli, logger),
+		Pod:                   NewPodService(kubecli, restConfig, logger),
+		PodDisruptionBudget:   NewPodDisruptionBudgetService(kubecli, logger),
+		Ingress:               NewIngressService(kubecli, logger),
 	}
 }
@@ -0,0 +1,63 @@
+package k8s
+
+import (
+	"context"
+	log "github.com/go-logr/logr"
+
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog"
+)
+
+type PodDisruptionBudget interface {
+	GetPodDisruptionBudget(namespace string, name string) (*policyv1.PodDisruptionBudget, error)
+	CreatePodDisruptionBudget(namespace string, pdb *policyv1.PodDisruptionBudget) error
+	UpdatePodDisruptionBudget(namespace string, pdb *policyv1.PodDisruptionBudget) error
+	// DeletePodDisruptionBudget 不存在时不返回错误
+	DeletePodDisruptionBudget(namespace string, name string) error
+}
+
+type PodDisruptionBudgetService struct {
+	kubeClient kubernetes.Interface
+	logger     log.Logger
+}
+
+func NewPodDisruptionBudgetService(kubeClient kubernetes.Interface, logger log.Logger) *PodDisruptionBudgetService {
+	logger = logger.WithValues("service", "k8s.poddisruptionbudget")
+	return &PodDisruptionBudgetService{
+		kubeClient: kubeClient,
+		logger:     logger,
+	}
+}
+
+func (s *PodDisruptionBudgetService) GetPodDisruptionBudget(namespace string, name string) (*policyv1.PodDisruptionBudget, error) {
+	pdb, err := s.kubeClient.PolicyV1().PodDisruptionBudgets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return pdb, err
+}
+
+func (s *PodDisruptionBudgetService) CreatePodDisruptionBudget(namespace string, pdb *policyv1.PodDisruptionBudget) error {
+	_, err := s.kubeClient.PolicyV1().PodDisruptionBudgets(namespace).Create(context.TODO(), pdb, metav1.CreateOptions{})
+	if err != nil {
+		return err
+	}
+	klog.V(2).Infof("create poddisruptionbudget,namespace: %s  name: %s", namespace, pdb.Name)
+	return nil
+}
+
+func (s *PodDisruptionBudgetService) UpdatePodDisruptionBudget(namespace string, pdb *policyv1.PodDisruptionBudget) error {
+	_, err := s.kubeClient.PolicyV1().PodDisruptionBudgets(namespace).Update(context.TODO(), pdb, metav1.UpdateOptions{})
+	return err
+}
+
+func (s *PodDisruptionBudgetService) DeletePodDisruptionBudget(namespace string, name string) error {
+	err := s.kubeClient.PolicyV1().PodDisruptionBudgets(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	log "github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// Pod the Pod service that knows how to interact with k8s to manage them
+type Pod interface {
+	GetPod(namespace string, name string) (*corev1.Pod, error)
+	DeletePod(namespace string, name string) error
+	ExecPod(namespace string, name string, container string, command []string) error
+}
+
+// PodService is the pod service implementation using API calls to kubernetes.
+type PodService struct {
+	kubeClient kubernetes.Interface
+	restConfig *rest.Config
+	logger     log.Logger
+}
+
+// NewPodService returns a new Pod KubeService.
+func NewPodService(kubeClient kubernetes.Interface, restConfig *rest.Config, logger log.Logger) *PodService {
+	logger = logger.WithValues("service", "k8s.pod")
+	return &PodService{
+		kubeClient: kubeClient,
+		restConfig: restConfig,
+		logger:     logger,
+	}
+}
+
+func (p *PodService) GetPod(namespace string, name string) (*corev1.Pod, error) {
+	pod, err := p.kubeClient.CoreV1().Pods(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return pod, err
+}
+
+func (p *PodService) DeletePod(namespace string, name string) error {
+	err := p.kubeClient.CoreV1().Pods(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+	if err != nil {
+		return err
+	}
+	p.logger.WithValues("namespace", namespace).WithValues("pod", name).Info("pod deleted")
+	return nil
+}
+
+// ExecPod runs the command in the container and waits for it to finish
+func (p *PodService) ExecPod(namespace string, name string, container string, command []string) error {
+	if p.restConfig == nil {
+		return fmt.Errorf("exec in pod %s/%s: rest config is not set", namespace, name)
+	}
+	req := p.kubeClient.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(namespace).
+		Name(name).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: container,
+			Command:   command,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+	executor, err := remotecommand.NewSPDYExecutor(p.restConfig, "POST", req.URL())
+	if err != nil {
+		return err
+	}
+	var stdout, stderr bytes.Buffer
+	if err := executor.Stream(remotecommand.StreamOptions{Stdout: &stdout, Stderr: &stderr}); err != nil {
+		return fmt.Errorf("exec %v in pod %s/%s: %v: %s", command, namespace, name, err, stderr.String())
+	}
+	p.logger.WithValues("namespace", namespace).WithValues("pod", name).Info("pod exec finished", "command", command)
+	return nil
+}
@@ -0,0 +1,53 @@
+package k8s
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var _ = Describe("PodService", func() {
+	var (
+		fakeClient *fake.Clientset
+		service    Pod
+		namespace  string
+		logger     = ctrl.Log.WithName("test")
+	)
+
+	BeforeEach(func() {
+		namespace = "default"
+		fakeClient = fake.NewSimpleClientset(&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "nacos-0", Namespace: namespace},
+		})
+		service = NewPodService(fakeClient, nil, logger)
+	})
+
+	Describe("DeletePod", func() {
+		It("should delete an existing Pod", func() {
+			_, err := service.GetPod(namespace, "nacos-0")
+			Expect(err).NotTo(HaveOccurred())
+
+			err = service.DeletePod(namespace, "nacos-0")
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = service.GetPod(namespace, "nacos-0")
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should return NotFound for a missing Pod", func() {
+			err := service.DeletePod(namespace, "nacos-9")
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+
+	Describe("ExecPod", func() {
+		It("should fail without a rest config", func() {
+			err := service.ExecPod(namespace, "nacos-0", "nacos", []string{"true"})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
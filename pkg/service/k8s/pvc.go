@@ -0,0 +1,70 @@
+package k8s
+
+import (
+	"context"
+
+	log "github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// PersistentVolumeClaim the PersistentVolumeClaim service that knows how to interact with k8s to manage them
+type PersistentVolumeClaim interface {
+	GetPersistentVolumeClaim(namespace string, name string) (*corev1.PersistentVolumeClaim, error)
+	ListPersistentVolumeClaims(namespace string) (*corev1.PersistentVolumeClaimList, error)
+	UpdatePersistentVolumeClaim(namespace string, pvc *corev1.PersistentVolumeClaim) error
+	DeletePersistentVolumeClaim(namespace string, name string) error
+	// GetStorageClass 用于判断 PVC 能否在线扩容
+	GetStorageClass(name string) (*storagev1.StorageClass, error)
+}
+
+// PersistentVolumeClaimService is the pvc service implementation using API calls to kubernetes.
+type PersistentVolumeClaimService struct {
+	kubeClient kubernetes.Interface
+	logger     log.Logger
+}
+
+// NewPersistentVolumeClaimService returns a new PersistentVolumeClaim KubeService.
+func NewPersistentVolumeClaimService(kubeClient kubernetes.Interface, logger log.Logger) *PersistentVolumeClaimService {
+	logger = logger.WithValues("service", "k8s.persistentVolumeClaim")
+	return &PersistentVolumeClaimService{
+		kubeClient: kubeClient,
+		logger:     logger,
+	}
+}
+
+func (p *PersistentVolumeClaimService) GetPersistentVolumeClaim(namespace string, name string) (*corev1.PersistentVolumeClaim, error) {
+	pvc, err := p.kubeClient.CoreV1().PersistentVolumeClaims(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return pvc, err
+}
+
+func (p *PersistentVolumeClaimService) ListPersistentVolumeClaims(namespace string) (*corev1.PersistentVolumeClaimList, error) {
+	return p.kubeClient.CoreV1().PersistentVolumeClaims(namespace).List(context.TODO(), metav1.ListOptions{})
+}
+
+func (p *PersistentVolumeClaimService) UpdatePersistentVolumeClaim(namespace string, pvc *corev1.PersistentVolumeClaim) error {
+	_, err := p.kubeClient.CoreV1().PersistentVolumeClaims(namespace).Update(context.TODO(), pvc, metav1.UpdateOptions{})
+	if err != nil {
+		return err
+	}
+	p.logger.WithValues("namespace", namespace).WithValues("persistentVolumeClaim", pvc.Name).Info("persistentVolumeClaim updated")
+	return nil
+}
+
+func (p *PersistentVolumeClaimService) DeletePersistentVolumeClaim(namespace string, name string) error {
+	err := p.kubeClient.CoreV1().PersistentVolumeClaims(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+	if err != nil {
+		return err
+	}
+	p.logger.WithValues("namespace", namespace).WithValues("persistentVolumeClaim", name).Info("persistentVolumeClaim deleted")
+	return nil
+}
+
+func (p *PersistentVolumeClaimService) GetStorageClass(name string) (*storagev1.StorageClass, error) {
+	return p.kubeClient.StorageV1().StorageClasses().Get(context.TODO(), name, metav1.GetOptions{})
+}
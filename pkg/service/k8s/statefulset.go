@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	log "github.com/go-logr/logr"
 	appsv1 "k8s.io/api/apps/v1"
@@ -25,6 +26,7 @@ type StatefulSet interface {
 	DeleteStatefulSet(namespace string, name string) error
 	ListStatefulSets(namespace string) (*appsv1.StatefulSetList, error)
 	GetStatefulSetReadPod(namespace, name string) ([]corev1.Pod, error)
+	GetStatefulSetPodReadiness(namespace, name string, minReadySeconds int32) ([]corev1.Pod, map[string]string, error)
 }
 
 // StatefulSetService is the service account service implementation using API calls to kubernetes.
@@ -65,24 +67,63 @@ func (s *StatefulSetService) GetStatefulSetPods(namespace, name string) (*corev1
 	return s.kubeClient.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: selector})
 }
 
+// GetStatefulSetReadPod returns the ready pods of the statefulset
 func (s *StatefulSetService) GetStatefulSetReadPod(namespace, name string) ([]corev1.Pod, error) {
+	pods, _, err := s.GetStatefulSetPodReadiness(namespace, name, 0)
+	return pods, err
+}
+
+// GetStatefulSetPodReadiness returns the ready pods of the statefulset and the reason why each other pod is not ready
+func (s *StatefulSetService) GetStatefulSetPodReadiness(namespace, name string, minReadySeconds int32) ([]corev1.Pod, map[string]string, error) {
 	var podlist []corev1.Pod
+	unready := map[string]string{}
 	podList, err := s.GetStatefulSetPods(namespace, name)
 	if err != nil {
-		return podlist, err
+		return podlist, unready, err
 	}
-	num := 0
+	now := time.Now()
 	for _, pod := range podList.Items {
-		if len(pod.Status.Conditions) != 4 {
+		if ready, reason := PodReadiness(&pod, minReadySeconds, now); ready {
+			podlist = append(podlist, pod)
+		} else {
+			unready[pod.Name] = reason
+		}
+	}
+	return podlist, unready, nil
+}
+
+// PodReadiness reports whether the pod is ready, looking up the Ready condition by type (it already
+// accounts for readiness gates). Terminating pods and pods ready for less than minReadySeconds are
+// not ready. The reason is empty for ready pods.
+func PodReadiness(pod *corev1.Pod, minReadySeconds int32, now time.Time) (bool, string) {
+	if pod.DeletionTimestamp != nil {
+		return false, "terminating"
+	}
+	if pod.Status.Phase != corev1.PodRunning {
+		return false, fmt.Sprintf("phase is %s", pod.Status.Phase)
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type != corev1.PodReady {
 			continue
 		}
-		if pod.Status.Conditions[1].Type == "Ready" &&
-			pod.Status.Conditions[1].Status == "True" {
-			num = num + 1
-			podlist = append(podlist, pod)
+		if condition.Status != corev1.ConditionTrue {
+			reason := fmt.Sprintf("Ready is %s", condition.Status)
+			if condition.Reason != "" {
+				reason += ": " + condition.Reason
+			}
+			if condition.Message != "" {
+				reason += ": " + condition.Message
+			}
+			return false, reason
+		}
+		if minReadySeconds > 0 && !condition.LastTransitionTime.IsZero() {
+			if readyFor := now.Sub(condition.LastTransitionTime.Time); readyFor < time.Duration(minReadySeconds)*time.Second {
+				return false, fmt.Sprintf("ready for %s, less than minReadySeconds %d", readyFor.Truncate(time.Second), minReadySeconds)
+			}
 		}
+		return true, ""
 	}
-	return podlist, nil
+	return false, "no Ready condition"
 }
 
 // CreateStatefulSet will create the given statefulset
@@ -127,7 +168,9 @@ func (s *StatefulSetService) CreateOrUpdateStatefulSet(namespace string, statefu
 		return s.UpdateStatefulSet(namespace, statefulSet)
 	//updates to statefulset spec for fields other than 'replicas', 'template', and 'updateStrategy' are forbidden
 	case Delete:
-		return s.DeleteStatefulSet(namespace, storedStatefulSet.Name)
+		// Orphan the pods so they keep running, the statefulset is created again on the next reconcile
+		// (triggered by the delete event) and adopts them.
+		return s.deleteStatefulSetOrphan(namespace, storedStatefulSet.Name)
 	}
 	return nil
 }
@@ -138,11 +181,24 @@ func (s *StatefulSetService) DeleteStatefulSet(namespace, name string) error {
 	return s.kubeClient.AppsV1().StatefulSets(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{PropagationPolicy: &propagation})
 }
 
+func (s *StatefulSetService) deleteStatefulSetOrphan(namespace, name string) error {
+	propagation := metav1.DeletePropagationOrphan
+	err := s.kubeClient.AppsV1().StatefulSets(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{PropagationPolicy: &propagation})
+	if err != nil {
+		return err
+	}
+	s.logger.WithValues("namespace", namespace).WithValues("statefulSet", name).Info("statefulSet deleted with pods orphaned, volumeClaimTemplates changed")
+	return nil
+}
+
 // ListStatefulSets will retrieve a list of statefulset in the given namespace
 func (s *StatefulSetService) ListStatefulSets(namespace string) (*appsv1.StatefulSetList, error) {
 	return s.kubeClient.AppsV1().StatefulSets(namespace).List(context.TODO(), metav1.ListOptions{})
 }
 
+// TemplateHashAnnotation records the hash of the rendered pod template, the statefulset is updated whenever it differs
+const TemplateHashAnnotation = "nacos.io/template-hash"
+
 type operator int
 
 const (
@@ -172,9 +228,27 @@ func checkSts(old *appsv1.StatefulSet, new *appsv1.StatefulSet) operator {
 		return Update
 	}
 
+	// 分批升级时逐个降低 partition
+	if partition(old) != partition(new) {
+		return Update
+	}
+
+	// any other change of the rendered template: image, probes, volumes, scheduling, k8sWrapper merges...
+	if hash := new.Annotations[TemplateHashAnnotation]; hash != "" && hash != old.Annotations[TemplateHashAnnotation] {
+		return Update
+	}
+
 	return None
 }
 
+// partition 返回 rollingUpdate.partition，未设置时为 0
+func partition(ss *appsv1.StatefulSet) int32 {
+	if rollingUpdate := ss.Spec.UpdateStrategy.RollingUpdate; rollingUpdate != nil && rollingUpdate.Partition != nil {
+		return *rollingUpdate.Partition
+	}
+	return 0
+}
+
 // check whether delete sts
 func checkVolumeClaimTemplates(old *appsv1.StatefulSet, new *appsv1.StatefulSet) bool {
 	ov := old.Spec.VolumeClaimTemplates
@@ -193,7 +267,9 @@ func checkVolumeClaimTemplates(old *appsv1.StatefulSet, new *appsv1.StatefulSet)
 
 		oscn := old.Spec.VolumeClaimTemplates[0].Spec.StorageClassName
 		nscn := new.Spec.VolumeClaimTemplates[0].Spec.StorageClassName
-		return !bytes.Equal(vmA, vmB) || !strings.EqualFold(*oscn, *nscn)
+		// an unset storageClassName keeps the class the claims were created with
+		storageClassChanged := nscn != nil && (oscn == nil || !strings.EqualFold(*oscn, *nscn))
+		return !bytes.Equal(vmA, vmB) || storageClassChanged
 	}
 
 	return false
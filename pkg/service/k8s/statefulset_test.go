@@ -3,11 +3,14 @@ package k8s
 import (
 	"context"
 	"fmt"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/fake"
 	"nacos.io/nacos-operator/test/testutil"
@@ -107,5 +110,99 @@ var _ = Describe("StatefulSetService", func() {
 			Expect(err).NotTo(HaveOccurred())
 			Expect(len(readyPods)).To(Equal(2))
 		})
+
+		It("should evaluate readiness by condition type and report unready reasons", func() {
+			labels := map[string]string{"app": "test-nacos", "middleware": "nacos"}
+			ss := testutil.NewStatefulSet("test-nacos", namespace, 4, labels)
+			err := service.CreateStatefulSet(namespace, ss)
+			Expect(err).NotTo(HaveOccurred())
+
+			longAgo := metav1.NewTime(time.Now().Add(-time.Hour))
+			// 带 readiness gate，条件顺序与数量都不同
+			gated := testutil.NewReadyPod("test-nacos-0", namespace, "test-nacos", "10.244.0.1")
+			gated.Status.Conditions = []corev1.PodCondition{
+				{Type: "example.com/gate", Status: corev1.ConditionTrue},
+				{Type: corev1.ContainersReady, Status: corev1.ConditionTrue},
+				{Type: corev1.PodScheduled, Status: corev1.ConditionTrue},
+				{Type: corev1.PodInitialized, Status: corev1.ConditionTrue},
+				{Type: corev1.PodReady, Status: corev1.ConditionTrue, LastTransitionTime: longAgo},
+			}
+			justReady := testutil.NewReadyPod("test-nacos-1", namespace, "test-nacos", "10.244.0.2")
+			justReady.Status.Conditions[0].LastTransitionTime = metav1.Now()
+			terminating := testutil.NewReadyPod("test-nacos-2", namespace, "test-nacos", "10.244.0.3")
+			terminating.DeletionTimestamp = &longAgo
+			terminating.Finalizers = []string{"example.com/keep"}
+			gateBlocked := testutil.NewReadyPod("test-nacos-3", namespace, "test-nacos", "10.244.0.4")
+			gateBlocked.Status.Conditions[0] = corev1.PodCondition{Type: corev1.PodReady, Status: corev1.ConditionFalse, Reason: "ReadinessGatesNotReady"}
+			for _, pod := range []*corev1.Pod{gated, justReady, terminating, gateBlocked} {
+				_, err := fakeClient.CoreV1().Pods(namespace).Create(context.TODO(), pod, metav1.CreateOptions{})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			readyPods, unready, err := service.GetStatefulSetPodReadiness(namespace, "test-nacos", 30)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(len(readyPods)).To(Equal(1))
+			Expect(readyPods[0].Name).To(Equal("test-nacos-0"))
+			Expect(unready).To(HaveLen(3))
+			Expect(unready["test-nacos-1"]).To(ContainSubstring("minReadySeconds"))
+			Expect(unready["test-nacos-2"]).To(Equal("terminating"))
+			Expect(unready["test-nacos-3"]).To(ContainSubstring("ReadinessGatesNotReady"))
+
+			readyPods, err = service.GetStatefulSetReadPod(namespace, "test-nacos")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(len(readyPods)).To(Equal(2))
+		})
+	})
+
+	Describe("CreateOrUpdateStatefulSet", func() {
+		It("should recreate the StatefulSet when volumeClaimTemplates change", func() {
+			labels := map[string]string{"app": "nacos"}
+			newStatefulSet := func(size string) *appsv1.StatefulSet {
+				ss := testutil.NewStatefulSet("test-nacos", namespace, 3, labels)
+				ss.Spec.VolumeClaimTemplates = []corev1.PersistentVolumeClaim{{
+					ObjectMeta: metav1.ObjectMeta{Name: "db"},
+					Spec: corev1.PersistentVolumeClaimSpec{
+						Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(size)}},
+					},
+				}}
+				return ss
+			}
+			Expect(service.CreateStatefulSet(namespace, newStatefulSet("10Gi"))).To(Succeed())
+
+			Expect(service.CreateOrUpdateStatefulSet(namespace, newStatefulSet("20Gi"))).To(Succeed())
+			_, err := service.GetStatefulSet(namespace, "test-nacos")
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+
+			// 删除事件触发的下一次调谐重建 StatefulSet
+			Expect(service.CreateOrUpdateStatefulSet(namespace, newStatefulSet("20Gi"))).To(Succeed())
+			Expect(service.CreateOrUpdateStatefulSet(namespace, newStatefulSet("20Gi"))).To(Succeed())
+			retrieved, err := service.GetStatefulSet(namespace, "test-nacos")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(retrieved.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests.Storage().String()).To(Equal("20Gi"))
+		})
+
+		It("should update the StatefulSet when the rollingUpdate partition changes", func() {
+			labels := map[string]string{"app": "nacos"}
+			newStatefulSet := func(partition int32) *appsv1.StatefulSet {
+				ss := testutil.NewStatefulSet("test-nacos", namespace, 3, labels)
+				ss.Spec.UpdateStrategy = appsv1.StatefulSetUpdateStrategy{
+					Type:          appsv1.RollingUpdateStatefulSetStrategyType,
+					RollingUpdate: &appsv1.RollingUpdateStatefulSetStrategy{Partition: &partition},
+				}
+				return ss
+			}
+			// 未设置 partition 与 partition 为 0 相同
+			Expect(service.CreateStatefulSet(namespace, testutil.NewStatefulSet("test-nacos", namespace, 3, labels))).To(Succeed())
+			fakeClient.ClearActions()
+			Expect(service.CreateOrUpdateStatefulSet(namespace, newStatefulSet(0))).To(Succeed())
+			for _, action := range fakeClient.Actions() {
+				Expect(action.GetVerb()).NotTo(Equal("update"))
+			}
+
+			Expect(service.CreateOrUpdateStatefulSet(namespace, newStatefulSet(2))).To(Succeed())
+			retrieved, err := service.GetStatefulSet(namespace, "test-nacos")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*retrieved.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(int32(2)))
+		})
 	})
 })
@@ -0,0 +1,126 @@
+package nacosClient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// 登录返回的 tokenTtl 与 Credentials.TokenTTL 都为空时使用的有效期，与 Nacos 默认值相同
+const DefaultTokenTTL = time.Second * 18000
+
+// Credentials operator 访问 Nacos 的认证信息
+type Credentials struct {
+	// token 缓存的键，通常为 CR 的 namespace/name
+	Key string
+	// 身份头，配置了 IdentitySecretRef 时使用，登录失败时仍可访问
+	IdentityKey   string
+	IdentityValue string
+	// 管理员账号，开启鉴权时登录获取 accessToken
+	Username string
+	Password string
+	// 登录未返回 tokenTtl 时使用的有效期，对应 nacos.core.auth.plugin.nacos.token.expire.seconds
+	TokenTTL time.Duration
+}
+
+func (c Credentials) login() bool {
+	return c.Username != "" && c.Password != ""
+}
+
+// apply 设置身份头与 accessToken
+func (c Credentials) apply(req *http.Request, token string) {
+	if c.IdentityKey != "" {
+		req.Header.Set(c.IdentityKey, c.IdentityValue)
+	}
+	if token != "" {
+		// 3.x 从请求头读取，1.x/2.x 从参数读取
+		req.Header.Set("accessToken", token)
+		query := req.URL.Query()
+		query.Set("accessToken", token)
+		req.URL.RawQuery = query.Encode()
+	}
+}
+
+type cachedToken struct {
+	mu       sync.Mutex
+	username string
+	password string
+	token    string
+	// 到期前刷新
+	refreshAt time.Time
+}
+
+type loginResult struct {
+	AccessToken string `json:"accessToken"`
+	TokenTTL    int64  `json:"tokenTtl"`
+	Data        *struct {
+		AccessToken string `json:"accessToken"`
+		TokenTTL    int64  `json:"tokenTtl"`
+	} `json:"data"`
+}
+
+// accessToken 返回缓存的 token，未登录、账号变更或即将过期时重新登录；未配置管理员账号时返回空
+func (c *NacosClient) accessToken(httpClient *http.Client, endpoint Endpoint, version, ip string, credentials Credentials) (string, error) {
+	if !credentials.login() {
+		return "", nil
+	}
+	value, _ := c.tokens.LoadOrStore(credentials.Key, &cachedToken{})
+	cached := value.(*cachedToken)
+	cached.mu.Lock()
+	defer cached.mu.Unlock()
+
+	now := time.Now()
+	if cached.token != "" && cached.username == credentials.Username && cached.password == credentials.Password && now.Before(cached.refreshAt) {
+		return cached.token, nil
+	}
+	token, ttl, err := c.login(httpClient, endpoint.LoginURL(ip, version), credentials)
+	if err != nil {
+		return "", err
+	}
+	if ttl <= 0 {
+		ttl = credentials.TokenTTL
+	}
+	if ttl <= 0 {
+		ttl = DefaultTokenTTL
+	}
+	cached.username, cached.password, cached.token = credentials.Username, credentials.Password, token
+	// 在有效期的 4/5 处刷新，避免使用即将过期的 token
+	cached.refreshAt = now.Add(ttl * 4 / 5)
+	return token, nil
+}
+
+// forgetToken 丢弃被服务端拒绝的 token
+func (c *NacosClient) forgetToken(credentials Credentials) {
+	c.tokens.Delete(credentials.Key)
+}
+
+func (c *NacosClient) login(httpClient *http.Client, loginURL string, credentials Credentials) (string, time.Duration, error) {
+	form := url.Values{"username": {credentials.Username}, "password": {credentials.Password}}
+	body, status, err := c.do(httpClient, http.MethodPost, loginURL, form, nil, credentials, "")
+	if err != nil {
+		return "", 0, err
+	}
+	// 服务端版本未知时先尝试 v3 登录接口，2.x 上不存在，由调用方尝试下一个版本
+	if status == http.StatusNotFound || status == http.StatusGone {
+		return "", 0, fmt.Errorf("login %s failed: %d: %w", loginURL, status, errAPINotFound)
+	}
+	if status != http.StatusOK {
+		return "", 0, fmt.Errorf("login %s failed: %d %s", loginURL, status, string(body))
+	}
+
+	result := loginResult{}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", 0, fmt.Errorf("login %s failed: %s ; body: %s", loginURL, err.Error(), string(body))
+	}
+	token, ttl := result.AccessToken, result.TokenTTL
+	if token == "" && result.Data != nil {
+		token, ttl = result.Data.AccessToken, result.Data.TokenTTL
+	}
+	if token == "" {
+		return "", 0, fmt.Errorf("login %s failed: no accessToken in response", loginURL)
+	}
+	return token, time.Duration(ttl) * time.Second, nil
+}
@@ -0,0 +1,173 @@
+package nacosClient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// 金丝雀检查使用的保留分组，业务不应使用
+const CanaryGroup = "NACOS_OPERATOR_CANARY"
+
+var (
+	configPaths = map[string]string{
+		APIV1: "/v1/cs/configs",
+		APIV2: "/v2/cs/config",
+		APIV3: "/v3/admin/cs/config",
+	}
+	instancePaths = map[string]string{
+		APIV1: "/v1/ns/instance",
+		APIV2: "/v2/ns/instance",
+		APIV3: "/v3/admin/ns/instance",
+	}
+)
+
+// ConfigCanary 在 CanaryGroup 中发布 dataId 后读回，校验内容一致，返回耗时
+func (c *NacosClient) ConfigCanary(endpoint Endpoint, ip string, credentials Credentials, dataId, content string) (time.Duration, error) {
+	return c.canary(endpoint, func(version string) error {
+		group := "group"
+		if version == APIV3 {
+			group = "groupName"
+		}
+		params := url.Values{"dataId": {dataId}, group: {CanaryGroup}}
+		publish := url.Values{"content": {content}}
+		for k, v := range params {
+			publish[k] = v
+		}
+		if _, err := c.expectOK(endpoint, version, ip, credentials, http.MethodPost, configPaths[version], publish); err != nil {
+			return err
+		}
+		body, err := c.expectOK(endpoint, version, ip, credentials, http.MethodGet, configPaths[version], params)
+		if errors.Is(err, errAPINotFound) {
+			// 发布成功后读取返回 404 表示配置不存在，不再尝试其他版本
+			return fmt.Errorf("config %s not found after publish", dataId)
+		}
+		if err != nil {
+			return err
+		}
+		got, err := parseConfigContent(version, body)
+		if err != nil {
+			return err
+		}
+		if got != content {
+			return fmt.Errorf("config %s read back %q, published %q", dataId, got, content)
+		}
+		return nil
+	})
+}
+
+// NamingCanary 在 CanaryGroup 中注册临时实例后查询，校验实例可见，结束后注销，返回耗时
+func (c *NacosClient) NamingCanary(endpoint Endpoint, ip string, credentials Credentials, serviceName, instanceIP string, instancePort int) (time.Duration, error) {
+	return c.canary(endpoint, func(version string) error {
+		instance := url.Values{
+			"serviceName": {serviceName},
+			"groupName":   {CanaryGroup},
+			"ip":          {instanceIP},
+			"port":        {strconv.Itoa(instancePort)},
+			"ephemeral":   {"true"},
+		}
+		if _, err := c.expectOK(endpoint, version, ip, credentials, http.MethodPost, instancePaths[version], instance); err != nil {
+			return err
+		}
+		// 临时实例没有心跳，注销失败也会被服务端过期清理
+		defer c.call(endpoint, version, ip, credentials, http.MethodDelete, instancePaths[version], instance)
+
+		query := url.Values{"serviceName": {serviceName}, "groupName": {CanaryGroup}}
+		body, err := c.expectOK(endpoint, version, ip, credentials, http.MethodGet, instancePaths[version]+"/list", query)
+		if err != nil {
+			return err
+		}
+		hosts, err := parseInstances(body)
+		if err != nil {
+			return err
+		}
+		for _, host := range hosts {
+			if host.IP == instanceIP && host.Port == instancePort {
+				return nil
+			}
+		}
+		return fmt.Errorf("instance %s:%d not found in service %s", instanceIP, instancePort, serviceName)
+	})
+}
+
+// canary 按 endpoint 的接口版本执行检查，接口不存在时尝试下一个版本
+func (c *NacosClient) canary(endpoint Endpoint, check func(version string) error) (time.Duration, error) {
+	start := time.Now()
+	var err error
+	for _, version := range endpoint.APIVersions() {
+		if err = check(version); !errors.Is(err, errAPINotFound) {
+			break
+		}
+	}
+	return time.Since(start), err
+}
+
+// expectOK 调用接口，状态码不是 200 时返回错误
+func (c *NacosClient) expectOK(endpoint Endpoint, version, ip string, credentials Credentials, method, path string, params url.Values) ([]byte, error) {
+	body, status, err := c.call(endpoint, version, ip, credentials, method, path, params)
+	if err != nil {
+		return body, err
+	}
+	if status != http.StatusOK {
+		return body, fmt.Errorf("%s %s: %d %s", method, path, status, string(body))
+	}
+	return body, nil
+}
+
+// parseConfigContent v1 直接返回内容，v2 的 data 为内容，v3 的 data 为配置详情
+func parseConfigContent(version string, body []byte) (string, error) {
+	if version == APIV1 {
+		return string(body), nil
+	}
+	result := struct {
+		Data json.RawMessage `json:"data"`
+	}{}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("%s ; body: %s", err.Error(), string(body))
+	}
+	var content string
+	if err := json.Unmarshal(result.Data, &content); err == nil {
+		return content, nil
+	}
+	detail := struct {
+		Content string `json:"content"`
+	}{}
+	if err := json.Unmarshal(result.Data, &detail); err != nil {
+		return "", fmt.Errorf("%s ; body: %s", err.Error(), string(body))
+	}
+	return detail.Content, nil
+}
+
+type canaryInstance struct {
+	IP   string `json:"ip"`
+	Port int    `json:"port"`
+}
+
+// parseInstances v1 返回 hosts，v2 的 data 中为 hosts，v3 的 data 为实例列表
+func parseInstances(body []byte) ([]canaryInstance, error) {
+	result := struct {
+		Hosts []canaryInstance `json:"hosts"`
+		Data  json.RawMessage  `json:"data"`
+	}{}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("%s ; body: %s", err.Error(), string(body))
+	}
+	if len(result.Data) == 0 || string(result.Data) == "null" {
+		return result.Hosts, nil
+	}
+	var hosts []canaryInstance
+	if err := json.Unmarshal(result.Data, &hosts); err == nil {
+		return hosts, nil
+	}
+	service := struct {
+		Hosts []canaryInstance `json:"hosts"`
+	}{}
+	if err := json.Unmarshal(result.Data, &service); err != nil {
+		return nil, fmt.Errorf("%s ; body: %s", err.Error(), string(body))
+	}
+	return service.Hosts, nil
+}
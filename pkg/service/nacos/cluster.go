@@ -1,108 +1,359 @@
 package nacosClient
 
 import (
+	"bytes"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"log"
+	"net"
 	"net/http"
+	"net/url"
 	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+// 默认的建连与读取超时，避免单个 Pod 无响应时阻塞调谐
+const (
+	DefaultConnectTimeout = time.Second * 2
+	DefaultReadTimeout    = time.Second * 5
 )
 
 type INacosClient interface {
 }
 
 type NacosClient struct {
-	logger     log.Logger
-	httpClient http.Client
+	logger         logr.Logger
+	httpClient     *http.Client
+	connectTimeout time.Duration
+	readTimeout    time.Duration
+	// Credentials.Key -> *cachedToken
+	tokens sync.Map
+	// TLSConfig.CacheKey -> *tlsClient，TLS 配置变化时替换
+	tlsClients sync.Map
+}
+
+// tlsClient 按 TLSConfig.digest 创建的 http client
+type tlsClient struct {
+	digest string
+	client *http.Client
+}
+
+// 零值 NacosClient 共用的 http client
+var defaultHTTPClient = newHTTPClient(DefaultConnectTimeout, DefaultReadTimeout, nil)
+
+// NewNacosClient 返回可复用的客户端，connectTimeout 限制建连，readTimeout 限制整个请求
+func NewNacosClient(logger logr.Logger, connectTimeout, readTimeout time.Duration) *NacosClient {
+	return &NacosClient{
+		logger:         logger,
+		httpClient:     newHTTPClient(connectTimeout, readTimeout, nil),
+		connectTimeout: connectTimeout,
+		readTimeout:    readTimeout,
+	}
+}
+
+// log 零值 NacosClient 没有 logger 时丢弃日志
+func (c *NacosClient) log() logr.Logger {
+	if c.logger == nil {
+		return logr.Discard()
+	}
+	return c.logger
+}
+
+func newHTTPClient(connectTimeout, readTimeout time.Duration, tlsConfig *tls.Config) *http.Client {
+	return &http.Client{
+		Timeout: readTimeout,
+		Transport: &http.Transport{
+			Proxy:                 http.ProxyFromEnvironment,
+			DialContext:           (&net.Dialer{Timeout: connectTimeout}).DialContext,
+			TLSClientConfig:       tlsConfig,
+			TLSHandshakeTimeout:   connectTimeout,
+			ResponseHeaderTimeout: readTimeout,
+			MaxIdleConnsPerHost:   2,
+			IdleConnTimeout:       time.Minute,
+		},
+	}
+}
+
+// client 返回访问 endpoint 使用的 http client，每个 TLSConfig.CacheKey 只保留一个，TLS 配置变化（如证书轮换）时替换
+func (c *NacosClient) client(endpoint Endpoint) (*http.Client, error) {
+	if endpoint.TLS == nil {
+		if c.httpClient != nil {
+			return c.httpClient, nil
+		}
+		return defaultHTTPClient, nil
+	}
+	digest := endpoint.TLS.digest()
+	if cached, ok := c.tlsClients.Load(endpoint.TLS.CacheKey); ok && cached.(*tlsClient).digest == digest {
+		return cached.(*tlsClient).client, nil
+	}
+	tlsConfig, err := endpoint.TLS.build()
+	if err != nil {
+		return nil, err
+	}
+	connectTimeout, readTimeout := c.connectTimeout, c.readTimeout
+	if connectTimeout <= 0 {
+		connectTimeout = DefaultConnectTimeout
+	}
+	if readTimeout <= 0 {
+		readTimeout = DefaultReadTimeout
+	}
+	client := newHTTPClient(connectTimeout, readTimeout, tlsConfig)
+	// 关闭旧配置的空闲连接，进行中的请求不受影响
+	if previous, loaded := c.tlsClients.Swap(endpoint.TLS.CacheKey, &tlsClient{digest: digest, client: client}); loaded {
+		previous.(*tlsClient).client.CloseIdleConnections()
+	}
+	return client, nil
 }
 
 type ServersInfo struct {
-	Code    int         `json:"code"`
-	Message interface{} `json:"message"`
-	Data    []struct {
-		IP         string `json:"ip"`
-		Port       int    `json:"port"`
-		State      string `json:"state"`
-		ExtendInfo struct {
-			LastRefreshTime int64 `json:"lastRefreshTime"`
-			RaftMetaData    struct {
-				MetaDataMap struct {
-					NamingInstanceMetadata struct {
-						Leader          string   `json:"leader"`
-						RaftGroupMember []string `json:"raftGroupMember"`
-						Term            int      `json:"term"`
-					} `json:"naming_instance_metadata"`
-					NamingPersistentServiceV2 struct {
-						Leader          string   `json:"leader"`
-						RaftGroupMember []string `json:"raftGroupMember"`
-						Term            int      `json:"term"`
-					} `json:"naming_persistent_service_v2"`
-					NamingServiceMetadata struct {
-						Leader          string   `json:"leader"`
-						RaftGroupMember []string `json:"raftGroupMember"`
-						Term            int      `json:"term"`
-					} `json:"naming_service_metadata"`
-				} `json:"metaDataMap"`
-			} `json:"raftMetaData"`
-			RaftPort       string `json:"raftPort"`
-			ReadyToUpgrade bool   `json:"readyToUpgrade"`
-			Version        string `json:"version"`
-		} `json:"extendInfo"`
-		Address       string `json:"address"`
-		FailAccessCnt int    `json:"failAccessCnt"`
-		Abilities     struct {
-			RemoteAbility struct {
-				SupportRemoteConnection bool `json:"supportRemoteConnection"`
-			} `json:"remoteAbility"`
-			ConfigAbility struct {
-				SupportRemoteMetrics bool `json:"supportRemoteMetrics"`
-			} `json:"configAbility"`
-			NamingAbility struct {
-				SupportJraft bool `json:"supportJraft"`
-			} `json:"namingAbility"`
-		} `json:"abilities"`
-	} `json:"data"`
-}
-
-// GetClusterNodes queries Nacos cluster nodes.
+	Code    int          `json:"code"`
+	Message interface{}  `json:"message"`
+	Data    []ServerInfo `json:"data"`
+}
+
+// ServerInfo 单个节点的信息
+type ServerInfo struct {
+	IP         string `json:"ip"`
+	Port       int    `json:"port"`
+	State      string `json:"state"`
+	ExtendInfo struct {
+		LastRefreshTime int64 `json:"lastRefreshTime"`
+		RaftMetaData    struct {
+			MetaDataMap struct {
+				NamingInstanceMetadata    RaftGroup `json:"naming_instance_metadata"`
+				NamingPersistentServiceV2 RaftGroup `json:"naming_persistent_service_v2"`
+				NamingServiceMetadata     RaftGroup `json:"naming_service_metadata"`
+			} `json:"metaDataMap"`
+		} `json:"raftMetaData"`
+		RaftPort       string `json:"raftPort"`
+		ReadyToUpgrade bool   `json:"readyToUpgrade"`
+		Version        string `json:"version"`
+	} `json:"extendInfo"`
+	Address       string `json:"address"`
+	FailAccessCnt int    `json:"failAccessCnt"`
+	Abilities     struct {
+		RemoteAbility struct {
+			SupportRemoteConnection bool `json:"supportRemoteConnection"`
+		} `json:"remoteAbility"`
+		ConfigAbility struct {
+			SupportRemoteMetrics bool `json:"supportRemoteMetrics"`
+		} `json:"configAbility"`
+		NamingAbility struct {
+			SupportJraft bool `json:"supportJraft"`
+		} `json:"namingAbility"`
+	} `json:"abilities"`
+}
+
+// RaftGroup 节点上报的 JRaft group 元数据
+type RaftGroup struct {
+	Leader          string   `json:"leader"`
+	RaftGroupMember []string `json:"raftGroupMember"`
+	Term            int      `json:"term"`
+}
+
+// Raft group 名称
+const (
+	RaftGroupNamingInstanceMetadata    = "naming_instance_metadata"
+	RaftGroupNamingPersistentServiceV2 = "naming_persistent_service_v2"
+	RaftGroupNamingServiceMetadata     = "naming_service_metadata"
+)
+
+// RaftGroups 返回节点上报的 Raft group，未上报（leader 与成员均为空）的 group 不返回
+func (s ServerInfo) RaftGroups() map[string]RaftGroup {
+	metaData := s.ExtendInfo.RaftMetaData.MetaDataMap
+	groups := map[string]RaftGroup{}
+	for name, group := range map[string]RaftGroup{
+		RaftGroupNamingInstanceMetadata:    metaData.NamingInstanceMetadata,
+		RaftGroupNamingPersistentServiceV2: metaData.NamingPersistentServiceV2,
+		RaftGroupNamingServiceMetadata:     metaData.NamingServiceMetadata,
+	} {
+		if group.Leader != "" || len(group.RaftGroupMember) > 0 {
+			groups[name] = group
+		}
+	}
+	return groups
+}
+
+// GetClusterNodes queries Nacos cluster nodes through the v1 API on the default port.
 // identity is optional; when provided as two elements [key, value], it is sent as a header.
 func (c *NacosClient) GetClusterNodes(ip string, identity ...string) (ServersInfo, error) {
-	servers := ServersInfo{}
-	//增加支持ipV6 pod状态探测
-	client := &http.Client{}
-	var err error
-	var url string
+	credentials := Credentials{}
+	if len(identity) >= 2 {
+		credentials.IdentityKey, credentials.IdentityValue = identity[0], identity[1]
+	}
+	return c.getClusterNodes(DefaultEndpoint(), APIV1, ip, credentials)
+}
 
-	if strings.Contains(ip, ":") {
-		url = fmt.Sprintf("http://[%s]:8848/nacos/v1/core/cluster/nodes", ip)
-	} else {
-		url = fmt.Sprintf("http://%s:8848/nacos/v1/core/cluster/nodes", ip)
+// GetClusterNodesAt 按 endpoint 的服务端版本选择接口查询集群节点，接口不存在时尝试下一个版本
+func (c *NacosClient) GetClusterNodesAt(endpoint Endpoint, ip string, credentials Credentials) (ServersInfo, error) {
+	var servers ServersInfo
+	var err error
+	for _, version := range endpoint.APIVersions() {
+		servers, err = c.getClusterNodes(endpoint, version, ip, credentials)
+		if !errors.Is(err, errAPINotFound) {
+			return servers, err
+		}
 	}
-	req, err := http.NewRequest("GET", url, nil)
+	return servers, err
+}
+
+func (c *NacosClient) getClusterNodes(endpoint Endpoint, version, ip string, credentials Credentials) (ServersInfo, error) {
+	servers := ServersInfo{}
+	body, status, err := c.call(endpoint, version, ip, credentials, http.MethodGet, clusterNodesPaths[version], nil)
 	if err != nil {
 		return servers, err
 	}
+	// 404/410 已在 send 中转换为 errAPINotFound，其余非 2xx 的响应体不是节点列表，直接返回
+	if status < http.StatusOK || status >= http.StatusMultipleChoices {
+		return servers, fmt.Errorf("instance: %s ; GET %s: %d %s", ip, clusterNodesPaths[version], status, string(body))
+	}
+	err = json.Unmarshal(body, &servers)
+	if err != nil {
+		c.log().V(1).Info("unexpected cluster nodes response", "instance", ip, "version", version, "body", string(body))
+		return servers, fmt.Errorf("instance: %s ; %s ; body: %s", ip, err.Error(), string(body))
+	}
+	return servers, nil
+}
 
-	if len(identity) >= 2 && identity[0] != "" {
-		header := http.Header{identity[0]: []string{identity[1]}}
-		req.Header = header
+// 成员离开集群的接口，只有 v1 版本
+const memberLeavePath = "/v1/core/cluster/server/leave"
+
+// LeaveMembers 请求 ip 上的成员将 addresses 移出集群，并由它通知其余成员。
+// addresses 与集群节点接口返回的 address 相同；服务端没有该接口时返回的错误满足 IsAPINotFound
+func (c *NacosClient) LeaveMembers(endpoint Endpoint, ip string, credentials Credentials, addresses []string) error {
+	payload, err := json.Marshal(addresses)
+	if err != nil {
+		return err
+	}
+	body, status, err := c.send(endpoint, APIV1, ip, credentials, http.MethodPost, memberLeavePath, nil, payload)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("instance: %s ; POST %s: %d %s", ip, memberLeavePath, status, string(body))
 	}
+	result := struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}{}
+	if err := json.Unmarshal(body, &result); err == nil && result.Code != 0 && result.Code != http.StatusOK {
+		return fmt.Errorf("instance: %s ; POST %s: %d %s", ip, memberLeavePath, result.Code, result.Message)
+	}
+	return nil
+}
+
+// 服务端不提供该版本的接口
+var errAPINotFound = errors.New("api not found")
 
-	resp, err := client.Do(req)
+// IsAPINotFound 服务端不提供该接口
+func IsAPINotFound(err error) bool {
+	return errors.Is(err, errAPINotFound)
+}
+
+// call 访问 ip 上 path 对应的接口，返回响应内容与状态码；接口不存在时返回 errAPINotFound。
+// 配置了管理员账号时携带 accessToken，token 被拒绝时重新登录一次
+func (c *NacosClient) call(endpoint Endpoint, version, ip string, credentials Credentials, method, path string, params url.Values) ([]byte, int, error) {
+	return c.send(endpoint, version, ip, credentials, method, path, params, nil)
+}
+
+// send 与 call 相同，jsonBody 不为 nil 时作为 JSON 请求体发送，params 放在查询参数中
+func (c *NacosClient) send(endpoint Endpoint, version, ip string, credentials Credentials, method, path string, params url.Values, jsonBody []byte) ([]byte, int, error) {
+	httpClient, err := c.client(endpoint)
 	if err != nil {
-		return servers, err
+		return nil, 0, err
+	}
+	token, err := c.accessToken(httpClient, endpoint, version, ip, credentials)
+	if err != nil {
+		if credentials.IdentityKey == "" {
+			return nil, 0, err
+		}
+		// 登录失败时只使用身份头
+		token = ""
+	}
+	target := endpoint.baseURL(ip) + path
+	body, status, err := c.do(httpClient, method, target, params, jsonBody, credentials, token)
+	if err == nil && token != "" && (status == http.StatusUnauthorized || status == http.StatusForbidden) {
+		c.forgetToken(credentials)
+		if token, err = c.accessToken(httpClient, endpoint, version, ip, credentials); err != nil {
+			return body, status, err
+		}
+		body, status, err = c.do(httpClient, method, target, params, jsonBody, credentials, token)
+	}
+	if err == nil && (status == http.StatusNotFound || status == http.StatusGone) {
+		return body, status, fmt.Errorf("instance: %s ; %s: %w", ip, target, errAPINotFound)
+	}
+	return body, status, err
+}
+
+// do 发送一次请求，POST 的参数放在表单中，其余放在查询参数中；有 jsonBody 时参数都放在查询参数中
+func (c *NacosClient) do(httpClient *http.Client, method, target string, params url.Values, jsonBody []byte, credentials Credentials, token string) ([]byte, int, error) {
+	var payload io.Reader
+	form := method == http.MethodPost && jsonBody == nil
+	if form {
+		payload = strings.NewReader(params.Encode())
+	} else if len(params) > 0 {
+		target += "?" + params.Encode()
+	}
+	if jsonBody != nil {
+		payload = bytes.NewReader(jsonBody)
+	}
+	req, err := http.NewRequest(method, target, payload)
+	if err != nil {
+		return nil, 0, err
+	}
+	if form {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	} else if jsonBody != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	credentials.apply(req, token)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, 0, err
 	}
 	defer resp.Body.Close()
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return servers, err
+		return nil, resp.StatusCode, err
 	}
+	return body, resp.StatusCode, nil
+}
 
-	err = json.Unmarshal(body, &servers)
-	if err != nil {
-		fmt.Printf("%s\n", body)
-		return servers, fmt.Errorf("instance: %s ; %s ; body: %s", ip, err.Error(), string(body))
+// ProbeResult 单个 Pod 的探测结果
+type ProbeResult struct {
+	IP      string
+	Servers ServersInfo
+	Err     error
+}
+
+// ProbeClusterNodes 并发查询多个 Pod 的集群节点，最多 workers 个请求同时进行，结果与 ips 顺序一致
+func (c *NacosClient) ProbeClusterNodes(endpoint Endpoint, ips []string, workers int, credentials Credentials) []ProbeResult {
+	results := make([]ProbeResult, len(ips))
+	if workers <= 0 || workers > len(ips) {
+		workers = len(ips)
 	}
-	return servers, nil
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				servers, err := c.GetClusterNodesAt(endpoint, ips[i], credentials)
+				results[i] = ProbeResult{IP: ips[i], Servers: servers, Err: err}
+			}
+		}()
+	}
+	for i := range ips {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return results
 }
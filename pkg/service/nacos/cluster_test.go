@@ -1,9 +1,19 @@
 package nacosClient
 
 import (
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 
+	"github.com/go-logr/logr"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"nacos.io/nacos-operator/test/testutil"
@@ -61,6 +71,288 @@ var _ = Describe("NacosClient", func() {
 			})
 		})
 
+		Context("with a versioned endpoint", func() {
+			It("should build the url from port, context path and api version", func() {
+				endpoint := Endpoint{Port: 8080, ContextPath: "/"}
+				Expect(endpoint.URL("10.0.0.1", APIV1)).To(Equal("http://10.0.0.1:8080/v1/core/cluster/nodes"))
+				endpoint = Endpoint{ContextPath: "custom/"}
+				Expect(endpoint.URL("fd00::1", APIV3)).To(Equal("http://[fd00::1]:8848/custom/v3/admin/core/cluster/node/list"))
+				Expect(Endpoint{ServerVersion: "v2.3.2"}.APIVersions()[0]).To(Equal(APIV2))
+				Expect(Endpoint{ServerVersion: "2.1.0"}.APIVersions()[0]).To(Equal(APIV1))
+				Expect(Endpoint{ServerVersion: "3.0.0-BETA"}.APIVersions()[0]).To(Equal(APIV3))
+			})
+
+			It("should fall back when the preferred api does not exist", func() {
+				server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					if r.URL.Path != "/custom/v2/core/cluster/node/list" {
+						w.WriteHeader(http.StatusNotFound)
+						return
+					}
+					w.Write([]byte(`{"code":0,"message":"success","data":[{"address":"127.0.0.1:8848","state":"UP"}]}`))
+				}))
+				defer server.Close()
+				port, _ := strconv.Atoi(server.URL[strings.LastIndex(server.URL, ":")+1:])
+
+				servers, err := client.GetClusterNodesAt(Endpoint{Port: port, ContextPath: "/custom", ServerVersion: "3.0.0"}, "127.0.0.1", Credentials{})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(len(servers.Data)).To(Equal(1))
+				Expect(servers.Data[0].State).To(Equal("UP"))
+
+				_, err = client.GetClusterNodesAt(Endpoint{Port: port, ContextPath: "/missing"}, "127.0.0.1", Credentials{})
+				Expect(err).To(HaveOccurred())
+			})
+			It("should return the response body when the server answers with an error status", func() {
+				server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusInternalServerError)
+					w.Write([]byte("caused: raft group not ready"))
+				}))
+				defer server.Close()
+				port, _ := strconv.Atoi(server.URL[strings.LastIndex(server.URL, ":")+1:])
+
+				_, err := client.GetClusterNodesAt(Endpoint{Port: port, ServerVersion: "2.1.0"}, "127.0.0.1", Credentials{})
+				Expect(err).To(HaveOccurred())
+				Expect(IsAPINotFound(err)).To(BeFalse())
+				Expect(err.Error()).To(ContainSubstring("500 caused: raft group not ready"))
+			})
+		})
+
+		Context("with admin credentials", func() {
+			It("should log in once, reuse the token and log in again when it is rejected", func() {
+				logins := 0
+				valid := ""
+				server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					switch r.URL.Path {
+					case "/nacos/v1/auth/login":
+						r.ParseForm()
+						if r.Form.Get("username") != "nacos" || r.Form.Get("password") != "secret" {
+							w.WriteHeader(http.StatusForbidden)
+							return
+						}
+						logins++
+						valid = fmt.Sprintf("token-%d", logins)
+						fmt.Fprintf(w, `{"accessToken":"%s","tokenTtl":18000,"globalAdmin":true}`, valid)
+					case "/nacos/v1/core/cluster/nodes":
+						if r.URL.Query().Get("accessToken") != valid {
+							w.WriteHeader(http.StatusForbidden)
+							w.Write([]byte(`{"code":403,"message":"token invalid"}`))
+							return
+						}
+						w.Write([]byte(`{"code":200,"data":[{"address":"127.0.0.1:8848","state":"UP"}]}`))
+					default:
+						w.WriteHeader(http.StatusNotFound)
+					}
+				}))
+				defer server.Close()
+				port, _ := strconv.Atoi(server.URL[strings.LastIndex(server.URL, ":")+1:])
+				endpoint := Endpoint{Port: port, ServerVersion: "2.1.0"}
+				credentials := Credentials{Key: "default/nacos", Username: "nacos", Password: "secret"}
+
+				for i := 0; i < 2; i++ {
+					servers, err := client.GetClusterNodesAt(endpoint, "127.0.0.1", credentials)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(len(servers.Data)).To(Equal(1))
+				}
+				Expect(logins).To(Equal(1))
+
+				// 服务端重启后旧 token 失效
+				valid = "expired"
+				servers, err := client.GetClusterNodesAt(endpoint, "127.0.0.1", credentials)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(len(servers.Data)).To(Equal(1))
+				Expect(logins).To(Equal(2))
+
+				credentials.Password = "wrong"
+				_, err = client.GetClusterNodesAt(endpoint, "127.0.0.1", credentials)
+				Expect(err).To(HaveOccurred())
+			})
+			It("should fall back to the v1 login when the server version is unknown", func() {
+				server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					switch r.URL.Path {
+					case "/nacos/v1/auth/login":
+						w.Write([]byte(`{"accessToken":"token","tokenTtl":18000}`))
+					case "/nacos/v2/core/cluster/node/list":
+						if r.URL.Query().Get("accessToken") != "token" {
+							w.WriteHeader(http.StatusForbidden)
+							return
+						}
+						w.Write([]byte(`{"code":0,"data":[{"address":"127.0.0.1:8848","state":"UP"}]}`))
+					default:
+						w.WriteHeader(http.StatusNotFound)
+					}
+				}))
+				defer server.Close()
+				port, _ := strconv.Atoi(server.URL[strings.LastIndex(server.URL, ":")+1:])
+
+				// 镜像 tag 为 latest 且未记录版本时先尝试 v3，2.x 没有 v3 登录接口
+				endpoint := Endpoint{Port: port}
+				Expect(endpoint.APIVersions()[0]).To(Equal(APIV3))
+				servers, err := client.GetClusterNodesAt(endpoint, "127.0.0.1", Credentials{Key: "default/nacos", Username: "nacos", Password: "secret"})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(len(servers.Data)).To(Equal(1))
+			})
+		})
+
+		Context("with tls", func() {
+			It("should verify the server certificate against the configured CA", func() {
+				server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.Write([]byte(`{"code":200,"data":[{"address":"127.0.0.1:8848","state":"UP"}]}`))
+				}))
+				defer server.Close()
+				port, _ := strconv.Atoi(server.URL[strings.LastIndex(server.URL, ":")+1:])
+				ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+				endpoint := Endpoint{Port: port, ServerVersion: "2.1.0", TLS: &TLSConfig{CacheKey: "default/nacos"}}
+				Expect(endpoint.URL("fd00::1", APIV1)).To(Equal(fmt.Sprintf("https://[fd00::1]:%d/nacos/v1/core/cluster/nodes", port)))
+				_, err := client.GetClusterNodesAt(endpoint, "127.0.0.1", Credentials{})
+				Expect(err).To(HaveOccurred())
+
+				endpoint.TLS = &TLSConfig{CacheKey: "default/nacos", CA: ca, ServerName: "example.com"}
+				servers, err := client.GetClusterNodesAt(endpoint, "127.0.0.1", Credentials{})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(len(servers.Data)).To(Equal(1))
+
+				endpoint.TLS = &TLSConfig{CacheKey: "default/nacos", InsecureSkipVerify: true}
+				_, err = client.GetClusterNodesAt(endpoint, "127.0.0.1", Credentials{})
+				Expect(err).NotTo(HaveOccurred())
+
+				endpoint.TLS = &TLSConfig{CacheKey: "default/nacos", CA: []byte("not a certificate")}
+				_, err = client.GetClusterNodesAt(endpoint, "127.0.0.1", Credentials{})
+				Expect(err).To(HaveOccurred())
+
+				// 每个 CR 只保留最近一份 TLS 配置的 http client
+				entries := 0
+				client.tlsClients.Range(func(key, value interface{}) bool {
+					entries++
+					Expect(key).To(Equal("default/nacos"))
+					Expect(value.(*tlsClient).digest).To(Equal((&TLSConfig{CacheKey: "default/nacos", InsecureSkipVerify: true}).digest()))
+					return true
+				})
+				Expect(entries).To(Equal(1))
+			})
+		})
+
+		Context("with canary checks", func() {
+			It("should publish and read back config and register and query an instance", func() {
+				configs := map[string]string{}
+				instances := map[string]bool{}
+				server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					r.ParseForm()
+					switch r.URL.Path {
+					case "/nacos/v2/cs/config":
+						key := r.Form.Get("group") + "/" + r.Form.Get("dataId")
+						if r.Method == http.MethodPost {
+							configs[key] = r.Form.Get("content")
+							w.Write([]byte(`{"code":0,"data":true}`))
+							return
+						}
+						fmt.Fprintf(w, `{"code":0,"data":%q}`, configs[key])
+					case "/nacos/v2/ns/instance":
+						key := r.Form.Get("groupName") + "/" + r.Form.Get("ip") + ":" + r.Form.Get("port")
+						instances[key] = r.Method == http.MethodPost
+						w.Write([]byte(`{"code":0,"data":"ok"}`))
+					case "/nacos/v2/ns/instance/list":
+						hosts := []string{}
+						for key, up := range instances {
+							if up && strings.HasPrefix(key, r.Form.Get("groupName")+"/") {
+								address := strings.SplitN(strings.TrimPrefix(key, r.Form.Get("groupName")+"/"), ":", 2)
+								hosts = append(hosts, fmt.Sprintf(`{"ip":%q,"port":%s}`, address[0], address[1]))
+							}
+						}
+						fmt.Fprintf(w, `{"code":0,"data":{"hosts":[%s]}}`, strings.Join(hosts, ","))
+					default:
+						w.WriteHeader(http.StatusNotFound)
+					}
+				}))
+				defer server.Close()
+				port, _ := strconv.Atoi(server.URL[strings.LastIndex(server.URL, ":")+1:])
+				endpoint := Endpoint{Port: port, ServerVersion: "2.3.2"}
+
+				_, err := client.ConfigCanary(endpoint, "127.0.0.1", Credentials{}, "canary", "hello")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(configs[CanaryGroup+"/canary"]).To(Equal("hello"))
+
+				_, err = client.NamingCanary(endpoint, "127.0.0.1", Credentials{}, "canary", "10.0.0.1", 8848)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(instances[CanaryGroup+"/10.0.0.1:8848"]).To(BeFalse())
+
+				// 服务端不支持任何版本的接口
+				_, err = client.ConfigCanary(Endpoint{Port: port, ContextPath: "/missing"}, "127.0.0.1", Credentials{}, "canary", "hello")
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
+		Context("when probing several pods", func() {
+			It("should bound slow pods by the timeout and keep the order", func() {
+				mockServers := testutil.CreateMockClusterServers(3, 0, "2.1.0")
+				mockServer := createMockServer(mockServers)
+				defer mockServer.Close()
+
+				client = NewNacosClient(logr.Discard(), time.Millisecond*200, time.Millisecond*500)
+				start := time.Now()
+				results := client.ProbeClusterNodes(DefaultEndpoint(), []string{"127.0.0.1", "192.0.2.1", "127.0.0.1"}, 2, Credentials{})
+				Expect(time.Since(start)).To(BeNumerically("<", time.Second*2))
+				Expect(len(results)).To(Equal(3))
+				Expect(results[0].Err).NotTo(HaveOccurred())
+				Expect(len(results[0].Servers.Data)).To(Equal(3))
+				Expect(results[1].IP).To(Equal("192.0.2.1"))
+				Expect(results[1].Err).To(HaveOccurred())
+				Expect(results[2].Err).NotTo(HaveOccurred())
+			})
+		})
+
+		Context("on an IPv6-only cluster", func() {
+			It("should probe pods by IPv6 address and report IPv6 members", func() {
+				listener, err := net.Listen("tcp6", "[::1]:0")
+				if err != nil {
+					Skip("IPv6 loopback is not available: " + err.Error())
+				}
+				members := `[{"ip":"fd00::1","port":8848,"state":"UP","address":"[fd00::1]:8848"},` +
+					`{"ip":"fd00::2","port":8848,"state":"UP","address":"[fd00::2]:8848"}]`
+				server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					if r.URL.Path != "/nacos/v2/core/cluster/node/list" {
+						w.WriteHeader(http.StatusNotFound)
+						return
+					}
+					fmt.Fprintf(w, `{"code":0,"message":"success","data":%s}`, members)
+				}))
+				server.Listener = listener
+				server.Start()
+				defer server.Close()
+				endpoint := Endpoint{Port: listener.Addr().(*net.TCPAddr).Port, ServerVersion: "2.3.2"}
+
+				results := client.ProbeClusterNodes(endpoint, []string{"::1", "::1"}, 2, Credentials{})
+				Expect(len(results)).To(Equal(2))
+				for _, result := range results {
+					Expect(result.Err).NotTo(HaveOccurred())
+					Expect(len(result.Servers.Data)).To(Equal(2))
+					Expect(result.Servers.Data[1].Address).To(Equal("[fd00::2]:8848"))
+				}
+			})
+		})
+
+		Context("when members leave the cluster", func() {
+			It("should post the addresses as json and report a missing api", func() {
+				var left []string
+				server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					if r.URL.Path != "/nacos/v1/core/cluster/server/leave" || r.Header.Get("Content-Type") != "application/json" {
+						w.WriteHeader(http.StatusNotFound)
+						return
+					}
+					Expect(json.NewDecoder(r.Body).Decode(&left)).To(Succeed())
+					w.Write([]byte(`{"code":200,"message":"ok","data":"ok"}`))
+				}))
+				defer server.Close()
+				port, _ := strconv.Atoi(server.URL[strings.LastIndex(server.URL, ":")+1:])
+
+				addresses := []string{"nacos-2.nacos-headless.default.svc.cluster.local:8848"}
+				Expect(client.LeaveMembers(Endpoint{Port: port}, "127.0.0.1", Credentials{}, addresses)).To(Succeed())
+				Expect(left).To(Equal(addresses))
+
+				err := client.LeaveMembers(Endpoint{Port: port, ContextPath: "/missing"}, "127.0.0.1", Credentials{}, addresses)
+				Expect(IsAPINotFound(err)).To(BeTrue())
+			})
+		})
+
 		// 因为用例要串行执行，用例多了执行很慢，下面用例都是可以跑过的
 		// 只是为了测试一个nacos_client，先跳过大部分用例
 		// Context("with identity headers", func() {
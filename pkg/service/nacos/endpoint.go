@@ -0,0 +1,122 @@
+package nacosClient
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// 集群节点接口的版本
+const (
+	APIV1 = "v1"
+	APIV2 = "v2"
+	APIV3 = "v3"
+)
+
+// 默认端口与上下文路径
+const (
+	DefaultPort        = 8848
+	DefaultContextPath = "/nacos"
+)
+
+var clusterNodesPaths = map[string]string{
+	APIV1: "/v1/core/cluster/nodes",
+	APIV2: "/v2/core/cluster/node/list",
+	APIV3: "/v3/admin/core/cluster/node/list",
+}
+
+// Endpoint Nacos 服务端的访问方式
+type Endpoint struct {
+	Port int
+	// server.servlet.contextPath，为空时使用 /nacos，"/" 表示根路径
+	ContextPath string
+	// 服务端版本，如 2.3.2，决定优先使用的接口版本
+	ServerVersion string
+	// 不为空时使用 https
+	TLS *TLSConfig
+}
+
+func DefaultEndpoint() Endpoint {
+	return Endpoint{Port: DefaultPort, ContextPath: DefaultContextPath}
+}
+
+// APIVersions 按服务端版本返回接口版本的尝试顺序：v2 接口从 2.2.0 开始提供，3.x 移除了 v1 接口。
+// 版本记录可能过时（如升级过程中），首选接口不存在时仍会尝试其余版本
+func (e Endpoint) APIVersions() []string {
+	major, minor, ok := parseVersion(e.ServerVersion)
+	switch {
+	case !ok || major >= 3:
+		return []string{APIV3, APIV2, APIV1}
+	case major == 2 && minor >= 2:
+		return []string{APIV2, APIV1, APIV3}
+	default:
+		return []string{APIV1, APIV2, APIV3}
+	}
+}
+
+// URL 返回 ip 上指定版本的集群节点接口地址
+func (e Endpoint) URL(ip, apiVersion string) string {
+	return e.baseURL(ip) + clusterNodesPaths[apiVersion]
+}
+
+// LoginURL 返回 ip 上与接口版本对应的登录地址，2.x 没有 v2 登录接口，使用 v1
+func (e Endpoint) LoginURL(ip, apiVersion string) string {
+	if apiVersion == APIV3 {
+		return e.baseURL(ip) + "/v3/auth/user/login"
+	}
+	return e.baseURL(ip) + "/v1/auth/login"
+}
+
+func (e Endpoint) baseURL(ip string) string {
+	//增加支持ipV6 pod状态探测
+	host := ip
+	if strings.Contains(ip, ":") {
+		host = fmt.Sprintf("[%s]", ip)
+	}
+	port := e.Port
+	if port <= 0 {
+		port = DefaultPort
+	}
+	scheme := "http"
+	if e.TLS != nil {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s:%d%s", scheme, host, port, e.contextPath())
+}
+
+// PathPrefix 控制台等 HTTP 路由使用的路径前缀，根路径为 "/"
+func (e Endpoint) PathPrefix() string {
+	if path := e.contextPath(); path != "" {
+		return path
+	}
+	return "/"
+}
+
+func (e Endpoint) contextPath() string {
+	if e.ContextPath == "" {
+		return DefaultContextPath
+	}
+	path := strings.TrimRight(e.ContextPath, "/")
+	if path != "" && !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return path
+}
+
+// parseVersion 解析 v2.3.2、3.0.0-BETA 之类的版本号
+func parseVersion(version string) (int, int, bool) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	parts := strings.SplitN(strings.SplitN(version, "-", 2)[0], ".", 3)
+	if len(parts) < 2 {
+		return 0, 0, false
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, false
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, false
+	}
+	return major, minor, true
+}
@@ -0,0 +1,57 @@
+package nacosClient
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+// TLSConfig 访问开启了 server.ssl 的 Nacos 时使用的 TLS 配置，证书均为 PEM 格式
+type TLSConfig struct {
+	// http client 缓存的键，通常为 CR 的 namespace/name
+	CacheKey string
+	// 校验服务端证书的 CA，为空时使用系统根证书
+	CA []byte
+	// 双向 TLS 的客户端证书与私钥
+	Cert []byte
+	Key  []byte
+	// 校验服务端证书时使用的名称，为空时使用请求地址（Pod IP）
+	ServerName         string
+	InsecureSkipVerify bool
+}
+
+func (t *TLSConfig) build() (*tls.Config, error) {
+	config := &tls.Config{
+		ServerName:         t.ServerName,
+		InsecureSkipVerify: t.InsecureSkipVerify,
+		MinVersion:         tls.VersionTLS12,
+	}
+	if len(t.CA) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(t.CA) {
+			return nil, errors.New("tls: no valid CA certificate found")
+		}
+		config.RootCAs = pool
+	}
+	if len(t.Cert) > 0 || len(t.Key) > 0 {
+		cert, err := tls.X509KeyPair(t.Cert, t.Key)
+		if err != nil {
+			return nil, fmt.Errorf("tls: invalid client certificate: %v", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
+
+// digest 标识一份 TLS 配置，证书轮换后使用新的 http client
+func (t *TLSConfig) digest() string {
+	h := sha256.New()
+	for _, part := range [][]byte{t.CA, t.Cert, t.Key, []byte(t.ServerName), []byte(fmt.Sprint(t.InsecureSkipVerify))} {
+		h.Write(part)
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,75 @@
+package operator
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	nacosgroupv1alpha1 "nacos.io/nacos-operator/api/v1alpha1"
+)
+
+// 金丝雀检查项，也是指标的 check 标签
+const (
+	CanaryConfig = "config"
+	CanaryNaming = "naming"
+)
+
+// 金丝雀检查使用的 dataId 与服务名，位于保留分组 NACOS_OPERATOR_CANARY
+const canaryName = "nacos-operator-canary"
+
+// 默认两次金丝雀检查的间隔
+const DefaultCanaryInterval = time.Minute
+
+func canaryInterval(nacos *nacosgroupv1alpha1.Nacos) time.Duration {
+	if nacos.Spec.Canary.IntervalSeconds > 0 {
+		return time.Duration(nacos.Spec.Canary.IntervalSeconds) * time.Second
+	}
+	return DefaultCanaryInterval
+}
+
+// canaryWait 距离下次金丝雀检查的时间，未开启时返回 0
+func canaryWait(nacos *nacosgroupv1alpha1.Nacos, now time.Time) time.Duration {
+	if !nacos.Spec.Canary.Enabled {
+		return 0
+	}
+	last := nacos.Status.Canary.LastCheckTime
+	if last.IsZero() {
+		return 0
+	}
+	if wait := last.Add(canaryInterval(nacos)).Sub(now); wait > 0 {
+		return wait
+	}
+	return 0
+}
+
+// CheckCanary 到达间隔时在第一个就绪 Pod 上发布并读回配置、注册并查询临时实例，结果写入 status.canary 与指标。
+// 返回是否执行了检查，检查失败不影响集群状态
+func (c *CheckClient) CheckCanary(nacos *nacosgroupv1alpha1.Nacos, pods []corev1.Pod, now time.Time) bool {
+	if !nacos.Spec.Canary.Enabled || len(pods) == 0 || canaryWait(nacos, now) > 0 {
+		return false
+	}
+	pod := pods[0]
+	status := nacosgroupv1alpha1.CanaryStatus{LastCheckTime: metav1.Time{Time: now}, PodName: pod.Name}
+	endpoint, credentials, err := c.access(nacos)
+	if err != nil {
+		status.Config = nacosgroupv1alpha1.CanaryResult{Message: err.Error()}
+		status.Naming = status.Config
+	} else {
+		latency, err := c.nacosClient.ConfigCanary(endpoint, pod.Status.PodIP, credentials, canaryName, now.UTC().Format(time.RFC3339Nano))
+		status.Config = canaryResult(latency, err)
+		latency, err = c.nacosClient.NamingCanary(endpoint, pod.Status.PodIP, credentials, canaryName, pod.Status.PodIP, endpoint.Port)
+		status.Naming = canaryResult(latency, err)
+	}
+	nacos.Status.Canary = status
+	observeCanary(nacos, CanaryConfig, status.Config)
+	observeCanary(nacos, CanaryNaming, status.Naming)
+	return true
+}
+
+func canaryResult(latency time.Duration, err error) nacosgroupv1alpha1.CanaryResult {
+	result := nacosgroupv1alpha1.CanaryResult{Success: err == nil, LatencyMilliseconds: latency.Milliseconds()}
+	if err != nil {
+		result.Message = err.Error()
+	}
+	return result
+}
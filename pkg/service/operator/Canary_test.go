@@ -0,0 +1,30 @@
+package operator
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	nacosgroupv1alpha1 "nacos.io/nacos-operator/api/v1alpha1"
+)
+
+func TestCanaryWait(t *testing.T) {
+	now := time.Now()
+	nacos := &nacosgroupv1alpha1.Nacos{}
+	if wait := canaryWait(nacos, now); wait != 0 {
+		t.Errorf("Expected no wait when canary is disabled, got %s", wait)
+	}
+
+	nacos.Spec.Canary = nacosgroupv1alpha1.CanarySpec{Enabled: true, IntervalSeconds: 30}
+	if wait := canaryWait(nacos, now); wait != 0 {
+		t.Errorf("Expected the first check to run immediately, got %s", wait)
+	}
+	nacos.Status.Canary.LastCheckTime = metav1.Time{Time: now.Add(-time.Second * 10)}
+	if wait := canaryWait(nacos, now); wait != time.Second*20 {
+		t.Errorf("Expected to wait 20s, got %s", wait)
+	}
+	nacos.Status.Canary.LastCheckTime = metav1.Time{Time: now.Add(-time.Minute)}
+	if wait := canaryWait(nacos, now); wait != 0 {
+		t.Errorf("Expected the check to be due, got %s", wait)
+	}
+}
@@ -2,7 +2,11 @@ package operator
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	k8stypes "k8s.io/apimachinery/pkg/types"
@@ -22,66 +26,129 @@ type ICheckClient interface {
 type CheckClient struct {
 	k8sService  k8s.Services
 	logger      log.Logger
-	nacosClient nacosClient.NacosClient
+	nacosClient *nacosClient.NacosClient
 	k8sClient   crclient.Client
 }
 
+// 同时探测的 Pod 数上限
+const nacosProbeWorkers = 5
+
 func NewCheckClient(logger log.Logger, k8sService k8s.Services, k8sClient crclient.Client) *CheckClient {
 	return &CheckClient{
-		k8sService: k8sService,
-		logger:     logger,
-		k8sClient:  k8sClient,
+		k8sService:  k8sService,
+		logger:      logger,
+		nacosClient: nacosClient.NewNacosClient(logger, nacosClient.DefaultConnectTimeout, nacosClient.DefaultReadTimeout),
+		k8sClient:   k8sClient,
 	}
 }
 
-func (c *CheckClient) CheckKind(nacos *nacosgroupv1alpha1.Nacos) []corev1.Pod {
+func (c *CheckClient) CheckKind(nacos *nacosgroupv1alpha1.Nacos) ([]corev1.Pod, error) {
 	// 保证ss数量和cr副本数匹配
 	ss, err := c.k8sService.GetStatefulSet(nacos.Namespace, nacos.Name)
-	myErrors.EnsureNormal(err)
-
-	if *ss.Spec.Replicas != *nacos.Spec.Replicas {
-		panic(myErrors.New(myErrors.CODE_ERR_UNKNOW, "cr replicas is not equal ss replicas"))
+	if err != nil {
+		return nil, err
+	}
 
+	replicas := int(clusterReplicas(nacos))
+	if int(*ss.Spec.Replicas) != replicas {
+		return nil, myErrors.New(myErrors.CODE_ERR_UNKNOW, "cr replicas is not equal ss replicas")
 	}
 
 	// 检查正常的pod数量，根据实际情况。如果单实例，必须要有1个;集群要1/2以上
-	pods, err := c.k8sService.GetStatefulSetReadPod(nacos.Namespace, nacos.Name)
-	if len(pods) < (int(*nacos.Spec.Replicas)+1)/2 {
-		panic(myErrors.New(myErrors.CODE_ERR_UNKNOW, "The number of ready pods is too less"))
-	} else if len(pods) != int(*nacos.Spec.Replicas) {
-		c.logger.V(0).Info("pod num is not right")
+	pods, unready, err := c.k8sService.GetStatefulSetPodReadiness(nacos.Namespace, nacos.Name, nacos.Spec.MinReadySeconds)
+	if err != nil {
+		return nil, err
+	}
+	nacos.Status.UnreadyPods = unreadyPods(unready)
+	if len(pods) < (replicas+1)/2 {
+		return nil, myErrors.New(myErrors.CODE_QUORUM_LOST, "The number of ready pods is too less: %d/%d ready%s",
+			len(pods), replicas, describeUnreadyPods(nacos.Status.UnreadyPods))
+	} else if len(pods) != replicas {
+		c.logger.V(0).Info("pod num is not right", "ready", len(pods), "replicas", replicas, "unready", describeUnreadyPods(nacos.Status.UnreadyPods))
+	}
+	return pods, nil
+}
+
+// unreadyPods 按 Pod 名称排序的未就绪原因
+func unreadyPods(unready map[string]string) []nacosgroupv1alpha1.UnreadyPod {
+	if len(unready) == 0 {
+		return nil
+	}
+	pods := make([]nacosgroupv1alpha1.UnreadyPod, 0, len(unready))
+	for name, reason := range unready {
+		pods = append(pods, nacosgroupv1alpha1.UnreadyPod{PodName: name, Reason: reason})
 	}
+	sort.Slice(pods, func(i, j int) bool { return pods[i].PodName < pods[j].PodName })
 	return pods
 }
 
-func (c *CheckClient) CheckNacos(nacos *nacosgroupv1alpha1.Nacos, pods []corev1.Pod) {
+func describeUnreadyPods(pods []nacosgroupv1alpha1.UnreadyPod) string {
+	if len(pods) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(pods))
+	for _, pod := range pods {
+		parts = append(parts, fmt.Sprintf("%s: %s", pod.PodName, pod.Reason))
+	}
+	return ", unready " + strings.Join(parts, "; ")
+}
+
+func (c *CheckClient) CheckNacos(nacos *nacosgroupv1alpha1.Nacos, pods []corev1.Pod) error {
 	leader := ""
-    nacos.Status.Conditions = []nacosgroupv1alpha1.NacosCondition{}
-    identityKey, identityValue := c.resolveIdentityHeader(nacos)
-	// 检查nacos是否访问通
+	nacos.Status.Instances = []nacosgroupv1alpha1.NacosCondition{}
+	endpoint, credentials, err := c.access(nacos)
+	if err != nil {
+		return err
+	}
+	ips := make([]string, 0, len(pods))
 	for _, pod := range pods {
-        servers, err := c.nacosClient.GetClusterNodes(pod.Status.PodIP, identityKey, identityValue)
-		myErrors.EnsureNormalMyError(err, myErrors.CODE_CLUSTER_FAILE)
+		ips = append(ips, pod.Status.PodIP)
+	}
+	// 并发检查nacos是否访问通，单个 Pod 超时只记录在自己的结果中
+	results := c.nacosClient.ProbeClusterNodes(endpoint, ips, nacosProbeWorkers, credentials)
+	failed := []string{}
+	observed := false
+	for i, pod := range pods {
+		if err := results[i].Err; err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", pod.Name, err))
+			nacos.Status.Instances = append(nacos.Status.Instances, nacosgroupv1alpha1.NacosCondition{
+				Status:   "false",
+				Instance: pod.Status.PodIP,
+				PodName:  pod.Name,
+				NodeName: pod.Spec.NodeName,
+			})
+			continue
+		}
+		servers := results[i].Servers
+		nacos.Status.Members = buildMembers(servers.Data, pods)
+		// 成员上报的 leader 不同，每次检查只观察一次，分裂持续一段时间后按分组记录脑裂
+		if !observed {
+			observed = true
+			if observePartitions(nacos, time.Now()) {
+				return myErrors.New(myErrors.CODE_SPLIT_BRAIN, "raft split brain: %s", describePartitions(nacos.Status.Partitions))
+			}
+		}
 		// 确保cr中实例个数和server数量相同
-		myErrors.EnsureEqual(len(servers.Data), int(*nacos.Spec.Replicas), myErrors.CODE_CLUSTER_FAILE, "server num is not equal")
+		if replicas := clusterReplicas(nacos); len(servers.Data) != int(replicas) {
+			return myErrors.New(myErrors.CODE_CLUSTER_FAILE, "server num is not equal: %d != %d", len(servers.Data), replicas)
+		}
 		for _, svc := range servers.Data {
-			myErrors.EnsureEqual(svc.State, "UP", myErrors.CODE_CLUSTER_FAILE, "node is not up")
-			if leader != "" {
-				// 确保每个节点leader相同
-				myErrors.EnsureEqual(leader, svc.ExtendInfo.RaftMetaData.MetaDataMap.NamingPersistentServiceV2.Leader,
-					myErrors.CODE_CLUSTER_FAILE, "leader not equal")
-			} else {
+			if svc.State != "UP" {
+				return myErrors.New(myErrors.CODE_CLUSTER_FAILE, "node %s is not up: %s", svc.Address, svc.State)
+			}
+			// 未脑裂时每个节点的 leader 相同
+			if leader == "" {
 				leader = svc.ExtendInfo.RaftMetaData.MetaDataMap.NamingPersistentServiceV2.Leader
 			}
 			nacos.Status.Version = svc.ExtendInfo.Version
 		}
 
-        condition := nacosgroupv1alpha1.NacosCondition{
-            Status:   "true",
-            Instance: pod.Status.PodIP,
-            PodName:  pod.Name,
-            NodeName: pod.Spec.NodeName,
-        }
+		condition := nacosgroupv1alpha1.NacosCondition{
+			Status:   "true",
+			Instance: pod.Status.PodIP,
+			PodName:  pod.Name,
+			NodeName: pod.Spec.NodeName,
+		}
 		leaderSplit := []string{}
 		if strings.Index(leader, ".") > 0 {
 			leaderSplit = strings.Split(leader, ".")
@@ -95,37 +162,145 @@ func (c *CheckClient) CheckNacos(nacos *nacosgroupv1alpha1.Nacos, pods []corev1.
 				condition.Type = "follower"
 			}
 		}
-		nacos.Status.Conditions = append(nacos.Status.Conditions, condition)
+		nacos.Status.Instances = append(nacos.Status.Instances, condition)
+	}
+	if len(failed) > 0 {
+		return myErrors.New(myErrors.CODE_CLUSTER_FAILE, "%d/%d pods unreachable: %s", len(failed), len(pods), strings.Join(failed, "; "))
 	}
+	return nil
+}
 
+// access 返回访问 Nacos 的地址与认证信息
+func (c *CheckClient) access(nacos *nacosgroupv1alpha1.Nacos) (nacosClient.Endpoint, nacosClient.Credentials, error) {
+	endpoint := nacosEndpoint(nacos)
+	tlsConfig, err := c.resolveTLS(nacos)
+	if err != nil {
+		return endpoint, nacosClient.Credentials{}, err
+	}
+	endpoint.TLS = tlsConfig
+	return endpoint, c.resolveCredentials(nacos), nil
 }
 
 // 解析身份头（从 Secret 中读取；如未配置或读取失败，则回退到 spec.certification）
 func (c *CheckClient) resolveIdentityHeader(nacos *nacosgroupv1alpha1.Nacos) (string, string) {
-    ref := nacos.Spec.IdentitySecretRef
-    if ref == nil || ref.Name == "" || c.k8sClient == nil {
-        // No identity configured; return empty (no header)
-        return "", ""
-    }
-    keyKey := ref.KeyKey
-    if keyKey == "" {
-        keyKey = "identity_key"
-    }
-    valKey := ref.ValueKey
-    if valKey == "" {
-        valKey = "identity_value"
-    }
-
-    var sec corev1.Secret
-    if err := c.k8sClient.Get(context.TODO(), k8stypes.NamespacedName{Namespace: nacos.Namespace, Name: ref.Name}, &sec); err != nil {
-        c.logger.V(0).Info("failed to read identity secret; skipping identity header", "name", ref.Name, "err", err)
-        return "", ""
-    }
-    bKey, ok1 := sec.Data[keyKey]
-    bVal, ok2 := sec.Data[valKey]
-    if !ok1 || !ok2 {
-        c.logger.V(0).Info("identity secret missing keys; skipping identity header", "keyKey", keyKey, "valueKey", valKey)
-        return "", ""
-    }
-    return string(bKey), string(bVal)
+	ref := nacos.Spec.IdentitySecretRef
+	if ref == nil || ref.Name == "" || c.k8sClient == nil {
+		// No identity configured; return empty (no header)
+		return "", ""
+	}
+	keyKey := ref.KeyKey
+	if keyKey == "" {
+		keyKey = "identity_key"
+	}
+	valKey := ref.ValueKey
+	if valKey == "" {
+		valKey = "identity_value"
+	}
+
+	var sec corev1.Secret
+	if err := c.k8sClient.Get(context.TODO(), k8stypes.NamespacedName{Namespace: nacos.Namespace, Name: ref.Name}, &sec); err != nil {
+		c.logger.V(0).Info("failed to read identity secret; skipping identity header", "name", ref.Name, "err", err)
+		return "", ""
+	}
+	bKey, ok1 := sec.Data[keyKey]
+	bVal, ok2 := sec.Data[valKey]
+	if !ok1 || !ok2 {
+		c.logger.V(0).Info("identity secret missing keys; skipping identity header", "keyKey", keyKey, "valueKey", valKey)
+		return "", ""
+	}
+	return string(bKey), string(bVal)
+}
+
+// 开启鉴权时使用 adminCredentialsSecretRef 中的账号登录获取 accessToken，配置了身份头时同时携带
+func (c *CheckClient) resolveCredentials(nacos *nacosgroupv1alpha1.Nacos) nacosClient.Credentials {
+	credentials := nacosClient.Credentials{Key: nacos.Namespace + "/" + nacos.Name}
+	credentials.IdentityKey, credentials.IdentityValue = c.resolveIdentityHeader(nacos)
+	if !nacos.Spec.Certification.Enabled {
+		return credentials
+	}
+	credentials.Username, credentials.Password = c.resolveAdminLogin(nacos)
+	if seconds, err := strconv.Atoi(nacos.Spec.Certification.TokenExpireSeconds); err == nil {
+		credentials.TokenTTL = time.Duration(seconds) * time.Second
+	}
+	return credentials
+}
+
+// 读取管理员账号与明文密码；未配置或读取失败时返回空，只使用身份头
+func (c *CheckClient) resolveAdminLogin(nacos *nacosgroupv1alpha1.Nacos) (string, string) {
+	ref := nacos.Spec.AdminCredentialsSecretRef
+	if ref.Name == "" || c.k8sClient == nil {
+		return "", ""
+	}
+	usernameKey := ref.UsernameKey
+	if usernameKey == "" {
+		usernameKey = "username"
+	}
+	passwordKey := ref.PasswordKey
+	if passwordKey == "" {
+		passwordKey = "password"
+	}
+
+	var sec corev1.Secret
+	if err := c.k8sClient.Get(context.TODO(), k8stypes.NamespacedName{Namespace: nacos.Namespace, Name: ref.Name}, &sec); err != nil {
+		c.logger.V(0).Info("failed to read admin secret; skipping login", "name", ref.Name, "err", err)
+		return "", ""
+	}
+	username, ok1 := sec.Data[usernameKey]
+	password, ok2 := sec.Data[passwordKey]
+	if !ok1 || !ok2 {
+		c.logger.V(0).Info("admin secret missing keys; skipping login", "usernameKey", usernameKey, "passwordKey", passwordKey)
+		return "", ""
+	}
+	return string(username), string(password)
+}
+
+// 读取 spec.tls 引用的证书，未配置时返回 nil 使用 http
+func (c *CheckClient) resolveTLS(nacos *nacosgroupv1alpha1.Nacos) (*nacosClient.TLSConfig, error) {
+	spec := nacos.Spec.TLS
+	if spec == nil {
+		return nil, nil
+	}
+	config := &nacosClient.TLSConfig{CacheKey: nacos.Namespace + "/" + nacos.Name, ServerName: spec.ServerName, InsecureSkipVerify: spec.InsecureSkipVerify}
+	if ref := spec.CASecretRef; ref != nil && ref.Name != "" {
+		data, err := c.readSecretKeys(nacos.Namespace, ref.Name, defaultString(ref.CertKey, "ca.crt"))
+		if err != nil {
+			return nil, err
+		}
+		config.CA = data[0]
+	}
+	if ref := spec.ClientCertSecretRef; ref != nil && ref.Name != "" {
+		data, err := c.readSecretKeys(nacos.Namespace, ref.Name, defaultString(ref.CertKey, corev1.TLSCertKey), defaultString(ref.KeyKey, corev1.TLSPrivateKeyKey))
+		if err != nil {
+			return nil, err
+		}
+		config.Cert, config.Key = data[0], data[1]
+	}
+	return config, nil
+}
+
+func (c *CheckClient) readSecretKeys(namespace, name string, keys ...string) ([][]byte, error) {
+	if c.k8sClient == nil {
+		return nil, myErrors.New(myErrors.CODE_ERR_SYSTEM, "no client to read secret %s/%s", namespace, name)
+	}
+	var sec corev1.Secret
+	if err := c.k8sClient.Get(context.TODO(), k8stypes.NamespacedName{Namespace: namespace, Name: name}, &sec); err != nil {
+		return nil, myErrors.New(myErrors.CODE_ERR_SYSTEM, "get secret %s/%s failed: %v", namespace, name, err)
+	}
+	data := make([][]byte, 0, len(keys))
+	for _, key := range keys {
+		value, ok := sec.Data[key]
+		// Secret 可能稍后补齐，重试而不是终止调谐
+		if !ok {
+			return nil, myErrors.New(myErrors.CODE_ERR_SYSTEM, "secret %s missing key %s", name, key)
+		}
+		data = append(data, value)
+	}
+	return data, nil
+}
+
+func defaultString(value, def string) string {
+	if value == "" {
+		return def
+	}
+	return value
 }
@@ -0,0 +1,263 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	nacosgroupv1alpha1 "nacos.io/nacos-operator/api/v1alpha1"
+	myErrors "nacos.io/nacos-operator/pkg/errors"
+)
+
+// HTTPRouteGVK Gateway API 没有引入 typed client，HTTPRoute 以 unstructured 管理
+var HTTPRouteGVK = schema.GroupVersionKind{Group: "gateway.networking.k8s.io", Version: "v1", Kind: "HTTPRoute"}
+
+// consoleBackend 返回控制台的后端 Service 与端口，跟随 spec.service.client 中 client 端口的修改
+func (e *KindClient) consoleBackend(nacos *nacosgroupv1alpha1.Nacos) (string, int32, error) {
+	ports, err := servicePorts(clientServicePorts, nacos.Spec.Service.Client.Ports, "nacos.Spec.Service.Client")
+	if err != nil {
+		return "", 0, err
+	}
+	for _, port := range ports {
+		if port.Name == "client" {
+			return e.generateClientSvcName(nacos), port.Port, nil
+		}
+	}
+	return "", 0, myErrors.New(myErrors.CODE_PARAMETER_ERROR, myErrors.MSG_PARAMETER_ERROT, "nacos.Spec.Service.Client.ports.client", "disabled")
+}
+
+// consolePath 未指定时使用 Nacos 的上下文路径
+func consolePath(nacos *nacosgroupv1alpha1.Nacos, path string) string {
+	if path == "" {
+		return nacosEndpoint(nacos).PathPrefix()
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return path
+}
+
+// EnsureConsoleIngress 开启时创建或更新控制台的 Ingress，关闭后删除由该 CR 创建的 Ingress
+func (e *KindClient) EnsureConsoleIngress(nacos *nacosgroupv1alpha1.Nacos) error {
+	name := e.generateName(nacos)
+	current, err := e.k8sService.GetIngress(nacos.Namespace, name)
+	if err != nil && !k8sErrors.IsNotFound(err) {
+		return err
+	}
+	if !nacos.Spec.Console.Ingress.Enabled {
+		if current == nil || !ownedBy(current, nacos) {
+			return nil
+		}
+		e.logger.Info("delete console ingress", "name", name)
+		return e.k8sService.DeleteIngress(nacos.Namespace, name)
+	}
+
+	desired, err := e.buildConsoleIngress(nacos)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		return e.k8sService.CreateIngress(nacos.Namespace, desired)
+	}
+	repaired := current.DeepCopy()
+	repairObjectMeta(&repaired.ObjectMeta, &desired.ObjectMeta)
+	repaired.Spec = desired.Spec
+	if equality.Semantic.DeepEqual(current, repaired) {
+		return nil
+	}
+	e.logger.Info("update console ingress", "name", name)
+	return e.k8sService.UpdateIngress(nacos.Namespace, repaired)
+}
+
+func (e *KindClient) buildConsoleIngress(nacos *nacosgroupv1alpha1.Nacos) (*networkingv1.Ingress, error) {
+	spec := nacos.Spec.Console.Ingress
+	service, port, err := e.consoleBackend(nacos)
+	if err != nil {
+		return nil, err
+	}
+	labels := e.MergeLabels(nacos.Labels, e.generateLabels(nacos.Name, NACOS))
+	pathType := networkingv1.PathTypePrefix
+
+	ingress := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        e.generateName(nacos),
+			Namespace:   nacos.Namespace,
+			Labels:      labels,
+			Annotations: e.MergeLabels(e.generateAnnoation(), spec.Annotations),
+		},
+		Spec: networkingv1.IngressSpec{
+			Rules: []networkingv1.IngressRule{
+				{
+					Host: spec.Host,
+					IngressRuleValue: networkingv1.IngressRuleValue{
+						HTTP: &networkingv1.HTTPIngressRuleValue{
+							Paths: []networkingv1.HTTPIngressPath{
+								{
+									Path:     consolePath(nacos, spec.Path),
+									PathType: &pathType,
+									Backend: networkingv1.IngressBackend{
+										Service: &networkingv1.IngressServiceBackend{
+											Name: service,
+											Port: networkingv1.ServiceBackendPort{Number: port},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	if spec.ClassName != "" {
+		className := spec.ClassName
+		ingress.Spec.IngressClassName = &className
+	}
+	if spec.TLSSecretName != "" {
+		tls := networkingv1.IngressTLS{SecretName: spec.TLSSecretName}
+		if spec.Host != "" {
+			tls.Hosts = []string{spec.Host}
+		}
+		ingress.Spec.TLS = []networkingv1.IngressTLS{tls}
+	}
+	if err := controllerutil.SetControllerReference(nacos, ingress, e.scheme); err != nil {
+		return nil, err
+	}
+	return ingress, nil
+}
+
+// EnsureConsoleHTTPRoute 开启时创建或更新控制台的 HTTPRoute，关闭后删除由该 CR 创建的 HTTPRoute；
+// 集群未安装 Gateway API 时，开启返回错误，关闭忽略
+func (e *KindClient) EnsureConsoleHTTPRoute(nacos *nacosgroupv1alpha1.Nacos) error {
+	name := e.generateName(nacos)
+	current := &unstructured.Unstructured{}
+	current.SetGroupVersionKind(HTTPRouteGVK)
+	err := e.client.Get(context.TODO(), types.NamespacedName{Namespace: nacos.Namespace, Name: name}, current)
+	found := err == nil
+	if err != nil && !k8sErrors.IsNotFound(err) {
+		if meta.IsNoMatchError(err) && !nacos.Spec.Console.HTTPRoute.Enabled {
+			return nil
+		}
+		if meta.IsNoMatchError(err) {
+			return fmt.Errorf("HTTPRoute %s is not available, install Gateway API CRDs first: %v", HTTPRouteGVK.GroupVersion(), err)
+		}
+		return err
+	}
+	if !nacos.Spec.Console.HTTPRoute.Enabled {
+		if !found || !ownedBy(current, nacos) {
+			return nil
+		}
+		e.logger.Info("delete console httproute", "name", name)
+		return e.client.Delete(context.TODO(), current)
+	}
+
+	desired, err := e.buildConsoleHTTPRoute(nacos)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return e.client.Create(context.TODO(), desired)
+	}
+	repaired := current.DeepCopy()
+	repaired.SetLabels(e.MergeLabels(current.GetLabels(), desired.GetLabels()))
+	if len(desired.GetAnnotations()) > 0 {
+		repaired.SetAnnotations(e.MergeLabels(current.GetAnnotations(), desired.GetAnnotations()))
+	}
+	if !ownedBy(current, nacos) {
+		repaired.SetOwnerReferences(append(current.GetOwnerReferences(), desired.GetOwnerReferences()...))
+	}
+	repaired.Object["spec"] = desired.Object["spec"]
+	if equality.Semantic.DeepEqual(current.Object, repaired.Object) {
+		return nil
+	}
+	e.logger.Info("update console httproute", "name", name)
+	return e.client.Update(context.TODO(), repaired)
+}
+
+// buildConsoleHTTPRoute 显式写出 apiserver 会填充的默认值（group、kind、weight），避免每次调谐都判定为变化
+func (e *KindClient) buildConsoleHTTPRoute(nacos *nacosgroupv1alpha1.Nacos) (*unstructured.Unstructured, error) {
+	spec := nacos.Spec.Console.HTTPRoute
+	if len(spec.ParentRefs) == 0 {
+		return nil, myErrors.New(myErrors.CODE_PARAMETER_ERROR, myErrors.MSG_PARAMETER_ERROT, "nacos.Spec.Console.HTTPRoute.parentRefs", "empty")
+	}
+	service, port, err := e.consoleBackend(nacos)
+	if err != nil {
+		return nil, err
+	}
+
+	parentRefs := []interface{}{}
+	for _, ref := range spec.ParentRefs {
+		parentRef := map[string]interface{}{
+			"group": HTTPRouteGVK.Group,
+			"kind":  "Gateway",
+			"name":  ref.Name,
+		}
+		if ref.Namespace != "" {
+			parentRef["namespace"] = ref.Namespace
+		}
+		if ref.SectionName != "" {
+			parentRef["sectionName"] = ref.SectionName
+		}
+		parentRefs = append(parentRefs, parentRef)
+	}
+	routeSpec := map[string]interface{}{
+		"parentRefs": parentRefs,
+		"rules": []interface{}{
+			map[string]interface{}{
+				"matches": []interface{}{
+					map[string]interface{}{
+						"path": map[string]interface{}{
+							"type":  "PathPrefix",
+							"value": consolePath(nacos, spec.Path),
+						},
+					},
+				},
+				"backendRefs": []interface{}{
+					map[string]interface{}{
+						"group":  "",
+						"kind":   "Service",
+						"name":   service,
+						"port":   int64(port),
+						"weight": int64(1),
+					},
+				},
+			},
+		},
+	}
+	if len(spec.Hostnames) > 0 {
+		hostnames := []interface{}{}
+		for _, hostname := range spec.Hostnames {
+			hostnames = append(hostnames, hostname)
+		}
+		routeSpec["hostnames"] = hostnames
+	}
+
+	route := &unstructured.Unstructured{Object: map[string]interface{}{"spec": routeSpec}}
+	route.SetGroupVersionKind(HTTPRouteGVK)
+	route.SetName(e.generateName(nacos))
+	route.SetNamespace(nacos.Namespace)
+	route.SetLabels(e.MergeLabels(nacos.Labels, e.generateLabels(nacos.Name, NACOS)))
+	if len(spec.Annotations) > 0 {
+		route.SetAnnotations(e.MergeLabels(e.generateAnnoation(), spec.Annotations))
+	}
+	if err := controllerutil.SetControllerReference(nacos, route, e.scheme); err != nil {
+		return nil, err
+	}
+	return route, nil
+}
+
+// ownedBy 对象的 controller 是否为该 CR，同名但不是 operator 创建的对象保持不变
+func ownedBy(object metav1.Object, nacos *nacosgroupv1alpha1.Nacos) bool {
+	owner := metav1.GetControllerOf(object)
+	return owner != nil && owner.UID == nacos.UID
+}
@@ -0,0 +1,97 @@
+package operator
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	nacosgroupv1alpha1 "nacos.io/nacos-operator/api/v1alpha1"
+)
+
+func TestEnsureConsoleIngress(t *testing.T) {
+	kindClient, fakeClient := newTestKindClient(nil)
+	nacos := newTestNacos("test-nacos")
+	nacos.Spec.Env = []v1.EnvVar{{Name: "SERVER_SERVLET_CONTEXTPATH", Value: "/console"}}
+	nacos.Spec.Console.Ingress = nacosgroupv1alpha1.ConsoleIngressSpec{
+		Enabled:       true,
+		ClassName:     "nginx",
+		Host:          "nacos.example.com",
+		TLSSecretName: "nacos-tls",
+	}
+	nacos.Spec.Service.Client.Ports = []nacosgroupv1alpha1.ServicePortSpec{{Name: "client", Port: 80}}
+
+	if err := kindClient.EnsureConsoleIngress(nacos); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	ingress, err := fakeClient.NetworkingV1().Ingresses("default").Get(context.TODO(), "test-nacos", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected ingress created: %v", err)
+	}
+	path := ingress.Spec.Rules[0].HTTP.Paths[0]
+	if path.Path != "/console" || path.Backend.Service.Name != "test-nacos-client" || path.Backend.Service.Port.Number != 80 {
+		t.Errorf("Unexpected path %+v", path)
+	}
+	if *ingress.Spec.IngressClassName != "nginx" || ingress.Spec.TLS[0].SecretName != "nacos-tls" || ingress.Spec.TLS[0].Hosts[0] != "nacos.example.com" {
+		t.Errorf("Unexpected ingress spec %+v", ingress.Spec)
+	}
+
+	nacos.Spec.Console.Ingress.Enabled = false
+	if err := kindClient.EnsureConsoleIngress(nacos); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := fakeClient.NetworkingV1().Ingresses("default").Get(context.TODO(), "test-nacos", metav1.GetOptions{}); err == nil {
+		t.Errorf("Expected ingress deleted after disable")
+	}
+}
+
+func TestEnsureConsoleHTTPRoute(t *testing.T) {
+	kindClient, _ := newTestKindClient(nil)
+	nacos := newTestNacos("test-nacos")
+	nacos.Spec.Env = []v1.EnvVar{{Name: "SERVER_SERVLET_CONTEXTPATH", Value: "/console"}}
+	nacos.Spec.Console.HTTPRoute = nacosgroupv1alpha1.ConsoleHTTPRouteSpec{
+		Enabled:    true,
+		ParentRefs: []nacosgroupv1alpha1.GatewayParentRef{{Name: "public", Namespace: "gateway", SectionName: "https"}},
+		Hostnames:  []string{"nacos.example.com"},
+	}
+
+	if err := kindClient.EnsureConsoleHTTPRoute(nacos); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	route := &unstructured.Unstructured{}
+	route.SetGroupVersionKind(HTTPRouteGVK)
+	key := types.NamespacedName{Namespace: "default", Name: "test-nacos"}
+	if err := kindClient.client.Get(context.TODO(), key, route); err != nil {
+		t.Fatalf("Expected httproute created: %v", err)
+	}
+	backendRefs, _, _ := unstructured.NestedSlice(route.Object, "spec", "rules")
+	rule := backendRefs[0].(map[string]interface{})
+	backend := rule["backendRefs"].([]interface{})[0].(map[string]interface{})
+	if backend["name"] != "test-nacos-client" || backend["port"] != int64(NACOS_PORT) {
+		t.Errorf("Unexpected backend %v", backend)
+	}
+	value, _, _ := unstructured.NestedString(rule["matches"].([]interface{})[0].(map[string]interface{}), "path", "value")
+	if value != "/console" {
+		t.Errorf("Expected path /console, got %s", value)
+	}
+
+	// 没有变化时不更新
+	version := route.GetResourceVersion()
+	if err := kindClient.EnsureConsoleHTTPRoute(nacos); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	_ = kindClient.client.Get(context.TODO(), key, route)
+	if route.GetResourceVersion() != version {
+		t.Errorf("Expected httproute unchanged, resourceVersion %s -> %s", version, route.GetResourceVersion())
+	}
+
+	nacos.Spec.Console.HTTPRoute.Enabled = false
+	if err := kindClient.EnsureConsoleHTTPRoute(nacos); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := kindClient.client.Get(context.TODO(), key, route); err == nil {
+		t.Errorf("Expected httproute deleted after disable")
+	}
+}
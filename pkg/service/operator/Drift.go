@@ -0,0 +1,165 @@
+package operator
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "k8s.io/api/core/v1"
+	myErrors "nacos.io/nacos-operator/pkg/errors"
+)
+
+// 漂移修复：子资源被手动修改后，只还原 operator 管理的字段（labels/annotations 中的 operator key、
+// ownerReference、端口、selector、data），其他控制器添加的字段保持不变
+
+// ensureServiceRepaired 创建 Service，已存在时修复 operator 管理的字段
+func (e *KindClient) ensureServiceRepaired(namespace string, desired *v1.Service) error {
+	current, err := e.k8sService.GetService(namespace, desired.Name)
+	if err != nil {
+		if k8sErrors.IsNotFound(err) {
+			return e.k8sService.CreateService(namespace, desired)
+		}
+		return err
+	}
+	if err := checkIPFamilies(current, desired); err != nil {
+		return err
+	}
+	repaired, changed := repairService(current, desired)
+	if !changed {
+		return nil
+	}
+	e.logger.Info("service drift detected, repairing", "name", desired.Name)
+	return e.k8sService.UpdateService(namespace, repaired)
+}
+
+// ensureConfigMapRepaired 创建 ConfigMap，已存在时修复 operator 管理的字段
+func (e *KindClient) ensureConfigMapRepaired(namespace string, desired *v1.ConfigMap) error {
+	current, err := e.k8sService.GetConfigMap(namespace, desired.Name)
+	if err != nil {
+		if k8sErrors.IsNotFound(err) {
+			return e.k8sService.CreateConfigMap(namespace, desired)
+		}
+		return err
+	}
+	repaired, changed := repairConfigMap(current, desired)
+	if !changed {
+		return nil
+	}
+	e.logger.Info("configmap drift detected, repairing", "name", desired.Name)
+	return e.k8sService.UpdateConfigMap(namespace, repaired)
+}
+
+// repairService 返回修复后的 Service 以及是否有变化
+func repairService(current, desired *v1.Service) (*v1.Service, bool) {
+	repaired := current.DeepCopy()
+	repairObjectMeta(&repaired.ObjectMeta, &desired.ObjectMeta)
+
+	desiredType := desired.Spec.Type
+	if desiredType == "" {
+		desiredType = v1.ServiceTypeClusterIP
+	}
+	repaired.Spec.Type = desiredType
+	repaired.Spec.Ports = repairServicePorts(current.Spec.Ports, desired.Spec.Ports, desiredType)
+	repaired.Spec.Selector = desired.Spec.Selector
+	repaired.Spec.PublishNotReadyAddresses = desired.Spec.PublishNotReadyAddresses
+	if desired.Spec.IPFamilyPolicy != nil {
+		repaired.Spec.IPFamilyPolicy = desired.Spec.IPFamilyPolicy
+	}
+	if len(desired.Spec.IPFamilies) > 0 {
+		repaired.Spec.IPFamilies = desired.Spec.IPFamilies
+	}
+	// 未指定时保留 apiserver 的默认值，切换为 ClusterIP 时必须清空
+	if desired.Spec.ExternalTrafficPolicy != "" || desiredType == v1.ServiceTypeClusterIP {
+		repaired.Spec.ExternalTrafficPolicy = desired.Spec.ExternalTrafficPolicy
+	}
+	if desiredType != v1.ServiceTypeLoadBalancer || repaired.Spec.ExternalTrafficPolicy != v1.ServiceExternalTrafficPolicyTypeLocal {
+		repaired.Spec.HealthCheckNodePort = 0
+	}
+	repaired.Spec.LoadBalancerSourceRanges = desired.Spec.LoadBalancerSourceRanges
+	// clusterIP 创建后不可修改，保持现状
+
+	return repaired, !equality.Semantic.DeepEqual(current, repaired)
+}
+
+// checkIPFamilies Service 的主协议族创建后不可修改，只能增减第二个协议族，否则每次更新都会被 apiserver 拒绝
+func checkIPFamilies(current, desired *v1.Service) error {
+	if len(desired.Spec.IPFamilies) == 0 || len(current.Spec.IPFamilies) == 0 || desired.Spec.IPFamilies[0] == current.Spec.IPFamilies[0] {
+		return nil
+	}
+	return myErrors.New(myErrors.CODE_PARAMETER_ERROR, myErrors.MSG_PARAMETER_ERROT, "nacos.Spec.Network.ipFamilies",
+		fmt.Sprintf("%v, the primary ip family of service %s is %s and cannot be changed, delete the service to recreate it",
+			desired.Spec.IPFamilies, current.Name, current.Spec.IPFamilies[0]))
+}
+
+// repairServicePorts 以期望的端口为准，保留 apiserver 分配的 nodePort 和默认的 targetPort
+func repairServicePorts(current, desired []v1.ServicePort, serviceType v1.ServiceType) []v1.ServicePort {
+	existing := map[string]v1.ServicePort{}
+	for _, port := range current {
+		existing[port.Name] = port
+	}
+	ports := make([]v1.ServicePort, 0, len(desired))
+	for _, port := range desired {
+		old, ok := existing[port.Name]
+		if ok && old.Port == port.Port {
+			if port.TargetPort.IntValue() == 0 && port.TargetPort.StrVal == "" {
+				port.TargetPort = old.TargetPort
+			}
+			if port.NodePort == 0 && serviceType != v1.ServiceTypeClusterIP {
+				port.NodePort = old.NodePort
+			}
+		}
+		ports = append(ports, port)
+	}
+	return ports
+}
+
+// repairConfigMap 返回修复后的 ConfigMap 以及是否有变化，其他控制器添加的 key 保留
+func repairConfigMap(current, desired *v1.ConfigMap) (*v1.ConfigMap, bool) {
+	repaired := current.DeepCopy()
+	repairObjectMeta(&repaired.ObjectMeta, &desired.ObjectMeta)
+	if len(desired.Data) > 0 && repaired.Data == nil {
+		repaired.Data = map[string]string{}
+	}
+	for k, v := range desired.Data {
+		repaired.Data[k] = v
+	}
+	if len(desired.BinaryData) > 0 && repaired.BinaryData == nil {
+		repaired.BinaryData = map[string][]byte{}
+	}
+	for k, v := range desired.BinaryData {
+		repaired.BinaryData[k] = v
+	}
+	return repaired, !equality.Semantic.DeepEqual(current, repaired)
+}
+
+// repairObjectMeta 还原 operator 设置的 labels、annotations 与 controller ownerReference
+func repairObjectMeta(current, desired *metav1.ObjectMeta) {
+	if len(desired.Labels) > 0 && current.Labels == nil {
+		current.Labels = map[string]string{}
+	}
+	for k, v := range desired.Labels {
+		current.Labels[k] = v
+	}
+	if len(desired.Annotations) > 0 && current.Annotations == nil {
+		current.Annotations = map[string]string{}
+	}
+	for k, v := range desired.Annotations {
+		current.Annotations[k] = v
+	}
+
+	for _, ref := range desired.OwnerReferences {
+		found := false
+		for i := range current.OwnerReferences {
+			if current.OwnerReferences[i].UID == ref.UID {
+				current.OwnerReferences[i] = ref
+				found = true
+				break
+			}
+		}
+		if !found {
+			current.OwnerReferences = append(current.OwnerReferences, ref)
+		}
+	}
+}
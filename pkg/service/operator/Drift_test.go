@@ -0,0 +1,127 @@
+package operator
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	myErrors "nacos.io/nacos-operator/pkg/errors"
+)
+
+func TestRepairService(t *testing.T) {
+	desired := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "nacos-client",
+			Labels: map[string]string{"app": "nacos"},
+		},
+		Spec: v1.ServiceSpec{
+			Type: v1.ServiceTypeNodePort,
+			Ports: []v1.ServicePort{
+				{Name: "client", Port: NACOS_PORT, Protocol: "TCP"},
+				{Name: "rpc", Port: 9848, Protocol: "TCP"},
+			},
+			Selector: map[string]string{"app": "nacos"},
+		},
+	}
+	current := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "nacos-client",
+			Labels:      map[string]string{"app": "nacos"},
+			Annotations: map[string]string{"other-controller/annotation": "kept"},
+		},
+		Spec: v1.ServiceSpec{
+			Type:      v1.ServiceTypeNodePort,
+			ClusterIP: "10.0.0.10",
+			Ports: []v1.ServicePort{
+				{Name: "client", Port: NACOS_PORT, Protocol: "TCP", TargetPort: intstr.FromInt(NACOS_PORT), NodePort: 30848},
+				{Name: "rpc", Port: 9848, Protocol: "TCP", TargetPort: intstr.FromInt(9848), NodePort: 31848},
+			},
+			Selector: map[string]string{"app": "nacos"},
+		},
+	}
+
+	// 未发生漂移时不需要更新
+	if _, changed := repairService(current, desired); changed {
+		t.Errorf("Expected no change for an up-to-date service")
+	}
+
+	// 手动修改端口与 selector
+	drifted := current.DeepCopy()
+	drifted.Spec.Ports = drifted.Spec.Ports[:1]
+	drifted.Spec.Selector = map[string]string{"app": "other"}
+	repaired, changed := repairService(drifted, desired)
+	if !changed {
+		t.Fatalf("Expected drift to be repaired")
+	}
+	if len(repaired.Spec.Ports) != 2 || repaired.Spec.Ports[1].Name != "rpc" {
+		t.Errorf("Expected rpc port restored, got %v", repaired.Spec.Ports)
+	}
+	if repaired.Spec.Ports[0].NodePort != 30848 {
+		t.Errorf("Expected allocated nodePort kept, got %d", repaired.Spec.Ports[0].NodePort)
+	}
+	if repaired.Spec.Selector["app"] != "nacos" {
+		t.Errorf("Expected selector restored, got %v", repaired.Spec.Selector)
+	}
+	if repaired.Spec.ClusterIP != "10.0.0.10" {
+		t.Errorf("Expected clusterIP kept, got %s", repaired.Spec.ClusterIP)
+	}
+	if repaired.Annotations["other-controller/annotation"] != "kept" {
+		t.Errorf("Expected foreign annotation kept, got %v", repaired.Annotations)
+	}
+}
+
+func TestRepairConfigMap(t *testing.T) {
+	desired := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "nacos-final-config"},
+		Data:       map[string]string{"application.properties": "server.port=8848"},
+	}
+	current := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "nacos-final-config"},
+		Data: map[string]string{
+			"application.properties": "server.port=9999",
+			"extra":                  "kept",
+		},
+	}
+
+	repaired, changed := repairConfigMap(current, desired)
+	if !changed {
+		t.Fatalf("Expected drift to be repaired")
+	}
+	if repaired.Data["application.properties"] != "server.port=8848" {
+		t.Errorf("Expected data restored, got %s", repaired.Data["application.properties"])
+	}
+	if repaired.Data["extra"] != "kept" {
+		t.Errorf("Expected foreign key kept, got %v", repaired.Data)
+	}
+	if _, changed := repairConfigMap(repaired, desired); changed {
+		t.Errorf("Expected no change after repair")
+	}
+}
+
+func TestCheckIPFamilies(t *testing.T) {
+	service := func(families ...v1.IPFamily) *v1.Service {
+		return &v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "nacos-client"}, Spec: v1.ServiceSpec{IPFamilies: families}}
+	}
+	tests := []struct {
+		name     string
+		current  *v1.Service
+		desired  *v1.Service
+		rejected bool
+	}{
+		{name: "unchanged", current: service(v1.IPv4Protocol), desired: service(v1.IPv4Protocol)},
+		{name: "cluster default", current: service(v1.IPv6Protocol), desired: service()},
+		{name: "add secondary family", current: service(v1.IPv4Protocol), desired: service(v1.IPv4Protocol, v1.IPv6Protocol)},
+		{name: "remove secondary family", current: service(v1.IPv4Protocol, v1.IPv6Protocol), desired: service(v1.IPv4Protocol)},
+		{name: "change primary family", current: service(v1.IPv4Protocol), desired: service(v1.IPv6Protocol), rejected: true},
+		{name: "swap dual stack order", current: service(v1.IPv4Protocol, v1.IPv6Protocol), desired: service(v1.IPv6Protocol, v1.IPv4Protocol), rejected: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkIPFamilies(tt.current, tt.desired)
+			if myErr, ok := err.(*myErrors.Err); tt.rejected != (ok && myErr.Code == myErrors.CODE_PARAMETER_ERROR) {
+				t.Errorf("Expected rejected=%v, got %v", tt.rejected, err)
+			}
+		})
+	}
+}
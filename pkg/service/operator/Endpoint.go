@@ -0,0 +1,73 @@
+package operator
+
+import (
+	"strconv"
+	"strings"
+
+	nacosgroupv1alpha1 "nacos.io/nacos-operator/api/v1alpha1"
+	nacosClient "nacos.io/nacos-operator/pkg/service/nacos"
+)
+
+// nacosEndpoint 从 CR 解析 Nacos 的端口与上下文路径，env 优先于 spec.config；
+// 服务端版本使用上次检查上报的版本，没有时从镜像 tag 推断
+func nacosEndpoint(nacos *nacosgroupv1alpha1.Nacos) nacosClient.Endpoint {
+	endpoint := nacosClient.DefaultEndpoint()
+	properties := parseProperties(nacos.Spec.Config)
+	if port, err := strconv.Atoi(properties["server.port"]); err == nil {
+		endpoint.Port = port
+	}
+	if path, ok := properties["server.servlet.contextPath"]; ok {
+		endpoint.ContextPath = path
+	}
+	for _, env := range nacos.Spec.Env {
+		switch env.Name {
+		case "NACOS_APPLICATION_PORT":
+			if port, err := strconv.Atoi(env.Value); err == nil {
+				endpoint.Port = port
+			}
+		case "SERVER_SERVLET_CONTEXTPATH":
+			if env.Value != "" {
+				endpoint.ContextPath = env.Value
+			}
+		}
+	}
+
+	endpoint.ServerVersion = nacos.Status.Version
+	if endpoint.ServerVersion == "" {
+		endpoint.ServerVersion = imageVersion(nacos.Spec.Image)
+	}
+	return endpoint
+}
+
+// parseProperties 解析 application.properties，跳过注释与 ${} 占位符
+func parseProperties(config string) map[string]string {
+	properties := map[string]string{}
+	for _, line := range strings.Split(config, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!") {
+			continue
+		}
+		kv := strings.SplitN(line, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		value := strings.TrimSpace(kv[1])
+		if strings.Contains(value, "${") {
+			continue
+		}
+		properties[strings.TrimSpace(kv[0])] = value
+	}
+	return properties
+}
+
+// imageVersion 返回镜像 tag，如 nacos/nacos-server:v2.3.2 返回 v2.3.2
+func imageVersion(image string) string {
+	if i := strings.Index(image, "@"); i >= 0 {
+		image = image[:i]
+	}
+	i := strings.LastIndex(image, ":")
+	if i < 0 || strings.Contains(image[i:], "/") {
+		return ""
+	}
+	return image[i+1:]
+}
@@ -0,0 +1,67 @@
+package operator
+
+import (
+	"testing"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	nacosgroupv1alpha1 "nacos.io/nacos-operator/api/v1alpha1"
+	nacosClient "nacos.io/nacos-operator/pkg/service/nacos"
+	ctrlfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestNacosEndpoint(t *testing.T) {
+	nacos := &nacosgroupv1alpha1.Nacos{Spec: nacosgroupv1alpha1.NacosSpec{Image: "registry:5000/nacos/nacos-server:v2.3.2"}}
+	if endpoint := nacosEndpoint(nacos); endpoint != (nacosClient.Endpoint{Port: 8848, ContextPath: "/nacos", ServerVersion: "v2.3.2"}) {
+		t.Errorf("Unexpected default endpoint: %+v", endpoint)
+	}
+
+	nacos.Spec.Config = "# server.port=1\nserver.port=8080\nserver.servlet.contextPath=${SERVER_SERVLET_CONTEXTPATH:/nacos}\n"
+	if endpoint := nacosEndpoint(nacos); endpoint.Port != 8080 || endpoint.ContextPath != "/nacos" {
+		t.Errorf("Expected port from config, got %+v", endpoint)
+	}
+
+	nacos.Spec.Env = []corev1.EnvVar{{Name: "NACOS_APPLICATION_PORT", Value: "9000"}, {Name: "SERVER_SERVLET_CONTEXTPATH", Value: "/"}}
+	nacos.Status.Version = "3.0.1"
+	if endpoint := nacosEndpoint(nacos); endpoint != (nacosClient.Endpoint{Port: 9000, ContextPath: "/", ServerVersion: "3.0.1"}) {
+		t.Errorf("Expected env and reported version to win, got %+v", endpoint)
+	}
+
+	if version := imageVersion("registry:5000/nacos/nacos-server"); version != "" {
+		t.Errorf("Expected no version without a tag, got %s", version)
+	}
+}
+
+func TestResolveTLS(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "nacos-client", Namespace: "default"},
+		Data:       map[string][]byte{corev1.TLSCertKey: []byte("cert")},
+	}
+	client := NewCheckClient(logr.Discard(), nil, ctrlfake.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build())
+	nacos := &nacosgroupv1alpha1.Nacos{
+		ObjectMeta: metav1.ObjectMeta{Name: "nacos", Namespace: "default"},
+		Spec: nacosgroupv1alpha1.NacosSpec{TLS: &nacosgroupv1alpha1.TLSSpec{
+			ClientCertSecretRef: &nacosgroupv1alpha1.TLSSecretRef{Name: "nacos-client"},
+		}},
+	}
+
+	// 缺少私钥时重试，不终止调谐
+	_, err := client.resolveTLS(nacos)
+	if result := ResultFromError(err); result.Action != ActionError {
+		t.Fatalf("Expected a retryable error for a missing key, got %+v", result)
+	}
+
+	secret.Data[corev1.TLSPrivateKeyKey] = []byte("key")
+	client.k8sClient = ctrlfake.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build()
+	config, err := client.resolveTLS(nacos)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if config.CacheKey != "default/nacos" || string(config.Cert) != "cert" || string(config.Key) != "key" {
+		t.Errorf("Unexpected tls config %+v", config)
+	}
+}
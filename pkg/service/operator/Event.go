@@ -0,0 +1,88 @@
+package operator
+
+import (
+	v1 "k8s.io/api/core/v1"
+	nacosgroupv1alpha1 "nacos.io/nacos-operator/api/v1alpha1"
+)
+
+// Kubernetes Event 的 reason，作为对外接口保持稳定，修改前需考虑依赖这些 reason 的告警规则
+const (
+	// Phase 变化
+	ReasonCreating = "Creating"
+	ReasonRunning  = "Running"
+	ReasonFailed   = "Failed"
+	ReasonScaling  = "Scaling"
+
+	// PG 初始化
+	ReasonPGInitialized = "PGInitialized"
+	ReasonPGInitFailed  = "PGInitFailed"
+
+	// 管理员口令轮转
+	ReasonAdminRotated      = "AdminRotated"
+	ReasonAdminRotateFailed = "AdminRotateFailed"
+
+	// 合并配置的 digest 变化
+	ReasonConfigChanged = "ConfigChanged"
+
+	// StatefulSet 副本数变化
+	ReasonScaled = "Scaled"
+
+	// StatefulSet 的 Pod 模板变化
+	ReasonTemplateChanged = "TemplateChanged"
+
+	// PVC 在线扩容
+	ReasonVolumeExpanded = "VolumeExpanded"
+
+	// Raft group 之间 leader 或成员不一致
+	ReasonRaftInconsistent = "RaftInconsistent"
+
+	// 成员上报的 leader 与 term 分为多组
+	ReasonSplitBrain = "SplitBrain"
+
+	// 失去多数派后的灾难恢复
+	ReasonRecoveryAwaitingConfirmation = "RecoveryAwaitingConfirmation"
+	ReasonRecoveryStarted              = "RecoveryStarted"
+	ReasonRecoveryGrowing              = "RecoveryGrowing"
+	ReasonRecoveryCompleted            = "RecoveryCompleted"
+
+	// 集群缩容
+	ReasonScaleDownDeferred   = "ScaleDownDeferred"
+	ReasonScaleDownStarted    = "ScaleDownStarted"
+	ReasonMembersLeft         = "MembersLeft"
+	ReasonMembersLeaveSkipped = "MembersLeaveSkipped"
+	ReasonScaleDownCompleted  = "ScaleDownCompleted"
+
+	// 分批升级
+	ReasonUpgradeStarted   = "UpgradeStarted"
+	ReasonMemberUpgraded   = "MemberUpgraded"
+	ReasonUpgradePaused    = "UpgradePaused"
+	ReasonUpgradeResumed   = "UpgradeResumed"
+	ReasonUpgradeCompleted = "UpgradeCompleted"
+
+	// 金丝雀检查
+	ReasonCanaryPassed = "CanaryPassed"
+	ReasonCanaryFailed = "CanaryFailed"
+
+	// Failed 时的自动修复操作
+	ReasonHealed = "Healed"
+
+	// 删除 CR 时的清理
+	ReasonFinalized      = "Finalized"
+	ReasonFinalizeFailed = "FinalizeFailed"
+)
+
+// phaseEventReasons Phase 与 Event reason 的对应关系，PhaseNone 不记录
+var phaseEventReasons = map[nacosgroupv1alpha1.Phase]string{
+	nacosgroupv1alpha1.PhaseCreating: ReasonCreating,
+	nacosgroupv1alpha1.PhaseRunning:  ReasonRunning,
+	nacosgroupv1alpha1.PhaseFailed:   ReasonFailed,
+	nacosgroupv1alpha1.PhaseScale:    ReasonScaling,
+}
+
+// phaseEventType Failed 记录为 Warning，其余为 Normal
+func phaseEventType(phase nacosgroupv1alpha1.Phase) string {
+	if phase == nacosgroupv1alpha1.PhaseFailed {
+		return v1.EventTypeWarning
+	}
+	return v1.EventTypeNormal
+}
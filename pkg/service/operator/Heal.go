@@ -1,21 +1,291 @@
 package operator
 
 import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
 	log "github.com/go-logr/logr"
+	appv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 	nacosgroupv1alpha1 "nacos.io/nacos-operator/api/v1alpha1"
 	"nacos.io/nacos-operator/pkg/service/k8s"
 )
 
+// 修复操作类型，记录在 status.heal.actions
+const (
+	// 节点上报 DOWN 或不在集群节点列表中，删除 Pod 由 StatefulSet 重启
+	HealRestartPod = "RestartPod"
+	// Pod 处于 CrashLoopBackOff 且重启次数超过阈值，删除后重建
+	HealRecreatePod = "RecreatePod"
+	// NACOS_SERVERS 与副本数不一致，由 MakeEnsure 重新渲染 StatefulSet
+	HealRenderStatefulSet = "RenderStatefulSet"
+	// 脑裂时清理少数派成员的 raft 数据并重启
+	HealResetRaft = "ResetRaft"
+)
+
+// Nacos 数据目录
+const NACOS_DATA_DIR = "/home/nacos/data"
+
+// raft 数据目录，清理后节点以空状态重新加入多数派
+const RAFT_PROTOCOL_DIR = NACOS_DATA_DIR + "/protocol"
+
+// 同一对象两次修复之间的默认最小间隔
+const DefaultHealInterval = time.Minute * 5
+
+// 默认的 CrashLoopBackOff 重启次数阈值
+const DefaultCrashLoopThreshold = 5
+
+const HEAL_ACTION_MAX_SIZE = 10
+
 type IHealClient interface {
 }
 
 type HealClient struct {
+	k8sService k8s.Services
+	logger     log.Logger
+	recorder   record.EventRecorder
+}
+
+func NewHealClient(logger log.Logger, k8sService k8s.Services, recorder record.EventRecorder) *HealClient {
+	return &HealClient{
+		k8sService: k8sService,
+		logger:     logger,
+		recorder:   recorder,
+	}
+}
+
+// MakeHeal 修复 Failed 状态的集群，每次最多执行一个修复操作，避免同时重启多个节点导致失去多数派。
+// 修复记录写入 status.heal，由后续的状态更新持久化
+func (c *HealClient) MakeHeal(nacos *nacosgroupv1alpha1.Nacos) error {
+	// 灾难恢复与缩容过程中成员数与 spec.replicas 不同，不做修复（等待升级完成的缩容成员数不变）；
+	// 升级中的成员由升级流程等待或暂停
+	if nacos.Spec.Heal.Disabled || recovering(nacos) || (scalingDown(nacos) && nacos.Status.Scale.Phase != ScalePending) ||
+		nacos.Status.Upgrade.Phase == UpgradeUpgrading {
+		return nil
+	}
+	ss, err := c.k8sService.GetStatefulSet(nacos.Namespace, nacos.Name)
+	if err != nil {
+		// StatefulSet 不存在时由 MakeEnsure 创建
+		if k8sErrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	now := time.Now()
+
+	if nacos.Spec.Type == TYPE_CLUSTER {
+		// MakeHeal 在 ValidationField 之前执行，不在此渲染，由同一次调谐的 MakeEnsure 按校验后的 spec 渲染，
+		// 模板更新后成员会滚动重启，不再重启其他 Pod
+		if reason := serversMismatch(nacos, ss); reason != "" {
+			if healAllowed(nacos, ss.Name, now) {
+				c.recordHeal(nacos, HealRenderStatefulSet, ss.Name, reason, now)
+			}
+			return nil
+		}
+	}
+
+	pods, err := c.k8sService.GetStatefulSetPods(nacos.Namespace, ss.Name)
+	if err != nil {
+		return err
+	}
+	if nacos.Spec.Heal.RaftRecovery {
+		if pod, reason := raftResetCandidate(nacos, pods.Items, now); pod != "" {
+			if err := c.k8sService.ExecPod(nacos.Namespace, pod, nacos.Name, []string{"rm", "-rf", RAFT_PROTOCOL_DIR}); err != nil {
+				return err
+			}
+			if err := c.k8sService.DeletePod(nacos.Namespace, pod); err != nil && !k8sErrors.IsNotFound(err) {
+				return err
+			}
+			c.recordHeal(nacos, HealResetRaft, pod, reason, now)
+			return nil
+		}
+	}
+
+	action, pod, reason := healCandidate(nacos, pods.Items, now)
+	if action == "" {
+		return nil
+	}
+	if err := c.k8sService.DeletePod(nacos.Namespace, pod); err != nil && !k8sErrors.IsNotFound(err) {
+		return err
+	}
+	c.recordHeal(nacos, action, pod, reason, now)
+	return nil
+}
+
+// recordHeal 记录修复操作，最多保留 HEAL_ACTION_MAX_SIZE 条
+func (c *HealClient) recordHeal(nacos *nacosgroupv1alpha1.Nacos, action, target, reason string, now time.Time) {
+	actions := append(nacos.Status.Heal.Actions, nacosgroupv1alpha1.HealAction{
+		Type:   action,
+		Target: target,
+		Reason: reason,
+		Time:   metav1.Time{Time: now},
+	})
+	if len(actions) > HEAL_ACTION_MAX_SIZE {
+		actions = actions[len(actions)-HEAL_ACTION_MAX_SIZE:]
+	}
+	nacos.Status.Heal.Actions = actions
+	c.logger.Info("heal nacos", "nacos", nacos.Name, "action", action, "target", target, "reason", reason)
+	c.recorder.Eventf(nacos, v1.EventTypeNormal, ReasonHealed, "%s %s: %s", action, target, reason)
+}
+
+// healCandidate 按 Pod 名称顺序选出第一个需要修复的 Pod，没有时返回空。
+// status.members 在 CheckNacos 前可能已过期，其余 Pod 未就绪或刚修复过时不修复，保证成员逐个重启
+func healCandidate(nacos *nacosgroupv1alpha1.Nacos, pods []v1.Pod, now time.Time) (string, string, string) {
+	action, pod, reason := unhealthyPod(nacos, pods, now)
+	if action == "" || !healSafe(nacos, pods, pod, now) {
+		return "", "", ""
+	}
+	return action, pod, reason
+}
+
+// unhealthyPod 按 Pod 名称顺序选出第一个需要修复的 Pod
+func unhealthyPod(nacos *nacosgroupv1alpha1.Nacos, pods []v1.Pod, now time.Time) (string, string, string) {
+	sorted := append([]v1.Pod{}, pods...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+	for _, pod := range sorted {
+		if pod.DeletionTimestamp != nil || !healAllowed(nacos, pod.Name, now) {
+			continue
+		}
+		if restarts, ok := crashLooping(pod, crashLoopThreshold(nacos)); ok {
+			return HealRecreatePod, pod.Name, fmt.Sprintf("CrashLoopBackOff after %d restarts", restarts)
+		}
+		// 节点列表为空时（例如查询失败）无法判断成员状态；未运行的 Pod 重启无意义
+		if len(nacos.Status.Members) == 0 || pod.Status.Phase != v1.PodRunning {
+			continue
+		}
+		member := memberOfPod(nacos.Status.Members, pod)
+		if member == nil {
+			return HealRestartPod, pod.Name, "missing from cluster nodes"
+		}
+		if member.State == "DOWN" {
+			return HealRestartPod, pod.Name, fmt.Sprintf("member %s is DOWN", member.Address)
+		}
+	}
+	return "", "", ""
+}
+
+// healSafe 其余 Pod 都已就绪且不在修复间隔内时才允许删除 target，否则可能失去多数派
+func healSafe(nacos *nacosgroupv1alpha1.Nacos, pods []v1.Pod, target string, now time.Time) bool {
+	if nacos.Spec.Replicas != nil && len(pods) < int(clusterReplicas(nacos)) {
+		return false
+	}
+	for _, pod := range pods {
+		if pod.Name != target && (!healAllowed(nacos, pod.Name, now) || !podReady(pod)) {
+			return false
+		}
+	}
+	return true
+}
+
+// raftResetCandidate 脑裂已确认且存在多数派时按 Pod 名称顺序选出第一个少数派成员。
+// 所有 Pod 就绪后才处理下一个，保证少数派成员逐个重启
+func raftResetCandidate(nacos *nacosgroupv1alpha1.Nacos, pods []v1.Pod, now time.Time) (string, string) {
+	majority := majorityPartition(nacos.Status.Partitions)
+	if majority == nil || !splitBrainConfirmed(nacos, now) || nacos.Spec.Replicas == nil || len(pods) < int(clusterReplicas(nacos)) {
+		return "", ""
+	}
+	for _, pod := range pods {
+		if !podReady(pod) {
+			return "", ""
+		}
+	}
+	minority := map[string]nacosgroupv1alpha1.RaftPartition{}
+	for _, partition := range nacos.Status.Partitions {
+		if partition.Majority {
+			continue
+		}
+		for _, address := range partition.Members {
+			minority[address] = partition
+		}
+	}
+
+	sorted := append([]v1.Pod{}, pods...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+	for _, pod := range sorted {
+		member := memberOfPod(nacos.Status.Members, pod)
+		if member == nil || !healAllowed(nacos, pod.Name, now) {
+			continue
+		}
+		if partition, ok := minority[member.Address]; ok {
+			return pod.Name, fmt.Sprintf("minority partition (leader %s term %d), majority leader %s term %d",
+				partition.Leader, partition.Term, majority.Leader, majority.Term)
+		}
+	}
+	return "", ""
+}
+
+func podReady(pod v1.Pod) bool {
+	ready, _ := k8s.PodReadiness(&pod, 0, time.Now())
+	return ready
+}
+
+// crashLooping 任一容器处于 CrashLoopBackOff 且重启次数达到阈值
+func crashLooping(pod v1.Pod, threshold int32) (int32, bool) {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Waiting != nil && status.State.Waiting.Reason == "CrashLoopBackOff" && status.RestartCount >= threshold {
+			return status.RestartCount, true
+		}
+	}
+	return 0, false
+}
+
+// memberOfPod 通过 Pod 名称、Pod IP 或主机名找到 Pod 对应的成员
+func memberOfPod(members []nacosgroupv1alpha1.NacosMember, pod v1.Pod) *nacosgroupv1alpha1.NacosMember {
+	for i, member := range members {
+		host := memberHost(member.Address)
+		if member.PodName == pod.Name ||
+			(pod.Status.PodIP != "" && host == pod.Status.PodIP) ||
+			strings.Split(host, ".")[0] == pod.Name {
+			return &members[i]
+		}
+	}
+	return nil
+}
+
+// serversMismatch StatefulSet 中 NACOS_SERVERS 的地址数与副本数不一致时返回原因
+func serversMismatch(nacos *nacosgroupv1alpha1.Nacos, ss *appv1.StatefulSet) string {
+	if nacos.Spec.Replicas == nil || len(ss.Spec.Template.Spec.Containers) == 0 {
+		return ""
+	}
+	for _, env := range ss.Spec.Template.Spec.Containers[0].Env {
+		if env.Name != "NACOS_SERVERS" {
+			continue
+		}
+		if servers, replicas := len(strings.Fields(env.Value)), clusterReplicas(nacos); servers != int(replicas) {
+			return fmt.Sprintf("NACOS_SERVERS has %d servers, replicas is %d", servers, replicas)
+		}
+		return ""
+	}
+	return "NACOS_SERVERS is missing"
 }
 
-func NewHealClient(logger log.Logger, k8sService k8s.Services) *HealClient {
-	return &HealClient{}
+// healAllowed 同一对象在修复间隔内只修复一次
+func healAllowed(nacos *nacosgroupv1alpha1.Nacos, target string, now time.Time) bool {
+	interval := DefaultHealInterval
+	if nacos.Spec.Heal.IntervalSeconds > 0 {
+		interval = time.Duration(nacos.Spec.Heal.IntervalSeconds) * time.Second
+	}
+	actions := nacos.Status.Heal.Actions
+	for i := len(actions) - 1; i >= 0; i-- {
+		if actions[i].Target == target {
+			return now.Sub(actions[i].Time.Time) >= interval
+		}
+	}
+	return true
 }
 
-func (c HealClient) MakeHeal(nacos *nacosgroupv1alpha1.Nacos) {
-	//TODO
+func crashLoopThreshold(nacos *nacosgroupv1alpha1.Nacos) int32 {
+	if nacos.Spec.Heal.CrashLoopThreshold > 0 {
+		return nacos.Spec.Heal.CrashLoopThreshold
+	}
+	return DefaultCrashLoopThreshold
 }
@@ -0,0 +1,127 @@
+package operator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	appv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+	nacosgroupv1alpha1 "nacos.io/nacos-operator/api/v1alpha1"
+	"nacos.io/nacos-operator/pkg/service/k8s"
+)
+
+func newHealPod(name, ip string) corev1.Pod {
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			PodIP:      ip,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+		},
+	}
+}
+
+func TestHealCandidate(t *testing.T) {
+	now := time.Now()
+	nacos := &nacosgroupv1alpha1.Nacos{}
+	nacos.Status.Members = []nacosgroupv1alpha1.NacosMember{
+		{Address: "10.0.0.1:8848", PodName: "nacos-0", State: "UP"},
+		{Address: "nacos-1.nacos-headless.default.svc.cluster.local:8848", State: "DOWN"},
+	}
+	pods := []corev1.Pod{
+		newHealPod("nacos-2", "10.0.0.3"),
+		newHealPod("nacos-1", "10.0.0.2"),
+		newHealPod("nacos-0", "10.0.0.1"),
+	}
+
+	// 按名称顺序，nacos-1 上报 DOWN
+	action, pod, _ := healCandidate(nacos, pods, now)
+	if action != HealRestartPod || pod != "nacos-1" {
+		t.Fatalf("Expected RestartPod nacos-1, got %s %s", action, pod)
+	}
+
+	// nacos-1 刚修复过，不连续重启不在节点列表中的 nacos-2
+	nacos.Status.Heal.Actions = []nacosgroupv1alpha1.HealAction{
+		{Type: HealRestartPod, Target: "nacos-1", Time: metav1.Time{Time: now.Add(-time.Minute)}},
+	}
+	if action, pod, _ := healCandidate(nacos, pods, now); action != "" {
+		t.Fatalf("Expected no action within the heal interval of nacos-1, got %s %s", action, pod)
+	}
+
+	// 超过修复间隔后允许再次修复
+	nacos.Spec.Heal.IntervalSeconds = 30
+	action, pod, _ = healCandidate(nacos, pods, now)
+	if action != HealRestartPod || pod != "nacos-1" {
+		t.Errorf("Expected nacos-1 to be healed again after interval, got %s %s", action, pod)
+	}
+
+	// nacos-1 重启后尚未就绪，不重启 nacos-2
+	nacos.Status.Members[1].State = "UP"
+	pods[1].Status.Conditions[0].Status = corev1.ConditionFalse
+	if action, pod, _ := healCandidate(nacos, pods, now); action != "" {
+		t.Fatalf("Expected no action while nacos-1 is unready, got %s %s", action, pod)
+	}
+	pods[1].Status.Conditions[0].Status = corev1.ConditionTrue
+	action, pod, reason := healCandidate(nacos, pods, now)
+	if action != HealRestartPod || pod != "nacos-2" || reason != "missing from cluster nodes" {
+		t.Fatalf("Expected RestartPod nacos-2 for missing member, got %s %s %s", action, pod, reason)
+	}
+
+	// 节点列表为空时只处理 CrashLoopBackOff
+	nacos.Status.Members = nil
+	if action, _, _ := healCandidate(nacos, pods, now); action != "" {
+		t.Errorf("Expected no action without members, got %s", action)
+	}
+	pods[0].Status.ContainerStatuses = []corev1.ContainerStatus{{
+		RestartCount: DefaultCrashLoopThreshold,
+		State:        corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+	}}
+	action, pod, _ = healCandidate(nacos, pods, now)
+	if action != HealRecreatePod || pod != "nacos-2" {
+		t.Errorf("Expected RecreatePod nacos-2, got %s %s", action, pod)
+	}
+}
+
+func TestServersMismatch(t *testing.T) {
+	replicas := int32(3)
+	nacos := &nacosgroupv1alpha1.Nacos{Spec: nacosgroupv1alpha1.NacosSpec{Replicas: &replicas}}
+	ss := &appv1.StatefulSet{}
+	ss.Spec.Template.Spec.Containers = []corev1.Container{{
+		Env: []corev1.EnvVar{{Name: "NACOS_SERVERS", Value: "nacos-0.nacos-headless:8848 nacos-1.nacos-headless:8848 nacos-2.nacos-headless:8848"}},
+	}}
+	if reason := serversMismatch(nacos, ss); reason != "" {
+		t.Errorf("Expected no mismatch, got %s", reason)
+	}
+
+	replicas = 5
+	if reason := serversMismatch(nacos, ss); reason != "NACOS_SERVERS has 3 servers, replicas is 5" {
+		t.Errorf("Expected mismatch, got %q", reason)
+	}
+
+	// MakeHeal 只记录，StatefulSet 由 MakeEnsure 按校验后的 spec 渲染
+	nacos.ObjectMeta = metav1.ObjectMeta{Name: "nacos", Namespace: "default"}
+	nacos.Spec.Type = TYPE_CLUSTER
+	ss.ObjectMeta = nacos.ObjectMeta
+	fakeClient := fake.NewSimpleClientset(ss.DeepCopy())
+	client := NewHealClient(logr.Discard(), k8s.NewK8sService(fakeClient, nil, logr.Discard()), record.NewFakeRecorder(10))
+	if err := client.MakeHeal(nacos); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if actions := nacos.Status.Heal.Actions; len(actions) != 1 || actions[0].Type != HealRenderStatefulSet {
+		t.Errorf("Expected a RenderStatefulSet action, got %+v", actions)
+	}
+	for _, action := range fakeClient.Actions() {
+		if action.GetVerb() != "get" && action.GetVerb() != "list" {
+			t.Errorf("Expected MakeHeal not to modify resources, got %s %s", action.GetVerb(), action.GetResource().Resource)
+		}
+	}
+
+	ss.Spec.Template.Spec.Containers[0].Env = nil
+	if reason := serversMismatch(nacos, ss); reason != "NACOS_SERVERS is missing" {
+		t.Errorf("Expected missing NACOS_SERVERS, got %q", reason)
+	}
+}
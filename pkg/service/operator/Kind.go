@@ -6,12 +6,15 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 
 	batchv1 "k8s.io/api/batch/v1"
-	"k8s.io/apimachinery/pkg/api/resource"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	"nacos.io/nacos-operator/pkg/util/merge"
 
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -66,13 +69,18 @@ type KindClient struct {
 	k8sService k8s.Services
 	logger     log.Logger
 	scheme     *runtime.Scheme
+	recorder   record.EventRecorder
+	// 管理没有 typed client 的资源，如 Gateway API 的 HTTPRoute
+	client client.Client
 }
 
-func NewKindClient(logger log.Logger, k8sService k8s.Services, scheme *runtime.Scheme) *KindClient {
+func NewKindClient(logger log.Logger, k8sService k8s.Services, scheme *runtime.Scheme, client client.Client, recorder record.EventRecorder) *KindClient {
 	return &KindClient{
 		k8sService: k8sService,
 		logger:     logger,
 		scheme:     scheme,
+		recorder:   recorder,
+		client:     client,
 	}
 }
 
@@ -212,60 +220,128 @@ func setDefaultMysql(nacos *nacosgroupv1alpha1.Nacos) {
 	}
 }
 
-func (e *KindClient) EnsureStatefulsetCluster(nacos *nacosgroupv1alpha1.Nacos) {
-	ss := e.buildStatefulset(nacos)
+func (e *KindClient) EnsureStatefulsetCluster(nacos *nacosgroupv1alpha1.Nacos) error {
+	ss, err := e.buildStatefulset(nacos)
+	if err != nil {
+		return err
+	}
 	ss = e.buildStatefulsetCluster(nacos, ss)
 	ss.Spec.Template.Spec = merge.PodSpec(ss.Spec.Template.Spec, nacos.Spec.K8sWrapper.PodSpec.Spec)
-	myErrors.EnsureNormal(e.k8sService.CreateOrUpdateStatefulSet(nacos.Namespace, ss))
+	return e.ensureStatefulSet(nacos, ss)
 }
 
-func (e *KindClient) EnsureStatefulset(nacos *nacosgroupv1alpha1.Nacos) {
-	ss := e.buildStatefulset(nacos)
+func (e *KindClient) EnsureStatefulset(nacos *nacosgroupv1alpha1.Nacos) error {
+	ss, err := e.buildStatefulset(nacos)
+	if err != nil {
+		return err
+	}
 	ss.Spec.Template.Spec = merge.PodSpec(ss.Spec.Template.Spec, nacos.Spec.K8sWrapper.PodSpec.Spec)
-	myErrors.EnsureNormal(e.k8sService.CreateOrUpdateStatefulSet(nacos.Namespace, ss))
+	return e.ensureStatefulSet(nacos, ss)
 }
 
-func (e *KindClient) EnsureService(nacos *nacosgroupv1alpha1.Nacos) {
-	ss := e.buildService(nacos)
-	myErrors.EnsureNormal(e.k8sService.CreateIfNotExistsService(nacos.Namespace, ss))
+func (e *KindClient) EnsureService(nacos *nacosgroupv1alpha1.Nacos) error {
+	ss, err := e.buildService(nacos)
+	if err != nil {
+		return err
+	}
+	if err := e.applyServiceOverride(ss, nacos.Spec.Service.Main, mainServicePorts, "nacos.Spec.Service.Main"); err != nil {
+		return err
+	}
+	return e.ensureServiceRepaired(nacos.Namespace, ss)
 }
 
-func (e *KindClient) EnsureServiceCluster(nacos *nacosgroupv1alpha1.Nacos) {
-	ss := e.buildService(nacos)
-	myErrors.EnsureNormal(e.k8sService.CreateOrUpdateService(nacos.Namespace, ss))
+func (e *KindClient) EnsureServiceCluster(nacos *nacosgroupv1alpha1.Nacos) error {
+	ss, err := e.buildService(nacos)
+	if err != nil {
+		return err
+	}
+	if err := e.applyServiceOverride(ss, nacos.Spec.Service.Main, mainServicePorts, "nacos.Spec.Service.Main"); err != nil {
+		return err
+	}
+	return e.ensureServiceRepaired(nacos.Namespace, ss)
 }
 
-func (e *KindClient) EnsureClientService(nacos *nacosgroupv1alpha1.Nacos) {
-	ss := e.buildClientService(nacos)
-	myErrors.EnsureNormal(e.k8sService.CreateOrUpdateService(nacos.Namespace, ss))
+func (e *KindClient) EnsureClientService(nacos *nacosgroupv1alpha1.Nacos) error {
+	ss, err := e.buildClientService(nacos)
+	if err != nil {
+		return err
+	}
+	if err := e.applyServiceOverride(ss, nacos.Spec.Service.Client, clientServicePorts, "nacos.Spec.Service.Client"); err != nil {
+		return err
+	}
+	return e.ensureServiceRepaired(nacos.Namespace, ss)
 }
 
-func (e *KindClient) EnsureHeadlessServiceCluster(nacos *nacosgroupv1alpha1.Nacos) {
-	ss := e.buildService(nacos)
+func (e *KindClient) EnsureHeadlessServiceCluster(nacos *nacosgroupv1alpha1.Nacos) error {
+	ss, err := e.buildService(nacos)
+	if err != nil {
+		return err
+	}
 	ss = e.buildHeadlessServiceCluster(ss, nacos)
-	myErrors.EnsureNormal(e.k8sService.CreateOrUpdateService(nacos.Namespace, ss))
+	if err := e.applyServiceOverride(ss, nacos.Spec.Service.Headless, mainServicePorts, "nacos.Spec.Service.Headless"); err != nil {
+		return err
+	}
+	return e.ensureServiceRepaired(nacos.Namespace, ss)
 }
 
-func (e *KindClient) EnsureConfigmap(nacos *nacosgroupv1alpha1.Nacos) {
+func (e *KindClient) EnsureConfigmap(nacos *nacosgroupv1alpha1.Nacos) error {
 	// 新的配置管理方式：合并 user-config 和 internal-config
 	if nacos.Spec.UserConfigRef != nil || nacos.Spec.InternalConfigRef != nil {
-		cm := e.buildMergedConfigMap(nacos)
-		myErrors.EnsureNormal(e.k8sService.CreateOrUpdateConfigMap(nacos.Namespace, cm))
+		cm, err := e.buildMergedConfigMap(nacos)
+		if err != nil {
+			return err
+		}
+		if err := e.ensureConfigMapRepaired(nacos.Namespace, cm); err != nil {
+			return err
+		}
 
 		// 计算配置的 digest 并保存到 Nacos status 中
 		if content, ok := cm.Data["application.properties"]; ok {
 			digest := e.computeConfigDigest(content)
+			if nacos.Status.ConfigDigest != "" && nacos.Status.ConfigDigest != digest {
+				e.recorder.Eventf(nacos, v1.EventTypeNormal, ReasonConfigChanged, "config digest changed from %s to %s", nacos.Status.ConfigDigest, digest)
+			}
 			nacos.Status.ConfigDigest = digest
 			e.logger.Info("Computed config digest", "digest", digest)
 		}
-		return
+		return nil
 	}
 
 	// 旧的配置方式：直接使用 Config 字段
 	if nacos.Spec.Config != "" {
-		cm := e.buildConfigMap(nacos)
-		myErrors.EnsureNormal(e.k8sService.CreateIfNotExistsConfigMap(nacos.Namespace, cm))
+		cm, err := e.buildConfigMap(nacos)
+		if err != nil {
+			return err
+		}
+		return e.ensureConfigMapRepaired(nacos.Namespace, cm)
 	}
+	return nil
+}
+
+// ensureStatefulSet 创建或更新 StatefulSet，模板或副本数变化时记录 Event
+func (e *KindClient) ensureStatefulSet(nacos *nacosgroupv1alpha1.Nacos, ss *appv1.StatefulSet) error {
+	current, err := e.k8sService.GetStatefulSet(nacos.Namespace, ss.Name)
+	if err != nil && !k8sErrors.IsNotFound(err) {
+		return err
+	}
+	keepVolumeClaimTemplates(nacos, current, ss)
+	hashes, err := setTemplateHash(ss)
+	if err != nil {
+		return err
+	}
+	if err := e.k8sService.CreateOrUpdateStatefulSet(nacos.Namespace, ss); err != nil {
+		return err
+	}
+	if current != nil && current.Annotations[k8s.TemplateHashAnnotation] != ss.Annotations[k8s.TemplateHashAnnotation] {
+		if fields := templateChangedFields(current, hashes); len(fields) > 0 {
+			e.logger.Info("statefulset template changed", "name", ss.Name, "fields", fields)
+			e.recorder.Eventf(nacos, v1.EventTypeNormal, ReasonTemplateChanged, "statefulset %s template changed: %s", ss.Name, strings.Join(fields, ", "))
+		}
+	}
+	if current != nil && current.Spec.Replicas != nil && ss.Spec.Replicas != nil && *current.Spec.Replicas != *ss.Spec.Replicas {
+		e.recorder.Eventf(nacos, v1.EventTypeNormal, ReasonScaled, "scale statefulset %s from %d to %d", ss.Name, *current.Spec.Replicas, *ss.Spec.Replicas)
+	}
+	return nil
 }
 
 // computeConfigDigest 计算配置内容的 SHA256 digest
@@ -279,20 +355,26 @@ func (e *KindClient) computeConfigDigest(content string) string {
 	return hash
 }
 
-func (e *KindClient) EnsureMysqlConfigMap(nacos *nacosgroupv1alpha1.Nacos) {
-	cm := e.buildMysqlConfigMap(nacos)
-	myErrors.EnsureNormal(e.k8sService.CreateIfNotExistsConfigMap(nacos.Namespace, cm))
+func (e *KindClient) EnsureMysqlConfigMap(nacos *nacosgroupv1alpha1.Nacos) error {
+	cm, err := e.buildMysqlConfigMap(nacos)
+	if err != nil {
+		return err
+	}
+	return e.ensureConfigMapRepaired(nacos.Namespace, cm)
 }
 
-func (e *KindClient) EnsureJob(nacos *nacosgroupv1alpha1.Nacos) {
+func (e *KindClient) EnsureJob(nacos *nacosgroupv1alpha1.Nacos) error {
 	// 使用job执行SQL脚本的逻辑
-	job := e.buildJob(nacos)
+	job, err := e.buildJob(nacos)
+	if err != nil {
+		return err
+	}
 	job.Spec.Template.Spec = merge.PodSpec(job.Spec.Template.Spec, nacos.Spec.K8sWrapper.PodSpec.Spec)
-	myErrors.EnsureNormal(e.k8sService.CreateIfNotExistsJob(nacos.Namespace, job))
+	return e.k8sService.CreateIfNotExistsJob(nacos.Namespace, job)
 }
 
 // buildSqlConfigMap 创建用于保存待导入的sql的configmap
-func (e *KindClient) buildMysqlConfigMap(nacos *nacosgroupv1alpha1.Nacos) *v1.ConfigMap {
+func (e *KindClient) buildMysqlConfigMap(nacos *nacosgroupv1alpha1.Nacos) (*v1.ConfigMap, error) {
 	labels := e.generateLabels(nacos.Name, NACOS)
 	labels = e.MergeLabels(nacos.Labels, labels)
 
@@ -308,11 +390,13 @@ func (e *KindClient) buildMysqlConfigMap(nacos *nacosgroupv1alpha1.Nacos) *v1.Co
 			"SQL_SCRIPT": readSql(SQL_FILE_NAME),
 		},
 	}
-	myErrors.EnsureNormal(controllerutil.SetControllerReference(nacos, cm, e.scheme))
-	return cm
+	if err := controllerutil.SetControllerReference(nacos, cm, e.scheme); err != nil {
+		return nil, err
+	}
+	return cm, nil
 }
 
-func (e *KindClient) buildJob(nacos *nacosgroupv1alpha1.Nacos) *batchv1.Job {
+func (e *KindClient) buildJob(nacos *nacosgroupv1alpha1.Nacos) (*batchv1.Job, error) {
 	labels := e.generateLabels(nacos.Name, NACOS)
 	labels = e.MergeLabels(nacos.Labels, labels)
 
@@ -446,8 +530,10 @@ func (e *KindClient) buildJob(nacos *nacosgroupv1alpha1.Nacos) *batchv1.Job {
 		},
 	}
 
-	myErrors.EnsureNormal(controllerutil.SetControllerReference(nacos, job, e.scheme))
-	return job
+	if err := controllerutil.SetControllerReference(nacos, job, e.scheme); err != nil {
+		return nil, err
+	}
+	return job, nil
 }
 
 func readSql(sqlFileName string) string {
@@ -462,7 +548,7 @@ func readSql(sqlFileName string) string {
 	return string(bytes)
 }
 
-func (e *KindClient) buildService(nacos *nacosgroupv1alpha1.Nacos) *v1.Service {
+func (e *KindClient) buildService(nacos *nacosgroupv1alpha1.Nacos) (*v1.Service, error) {
 	labels := e.generateLabels(nacos.Name, NACOS)
 	labels = e.MergeLabels(nacos.Labels, labels)
 
@@ -477,31 +563,18 @@ func (e *KindClient) buildService(nacos *nacosgroupv1alpha1.Nacos) *v1.Service {
 		},
 		Spec: v1.ServiceSpec{
 			PublishNotReadyAddresses: true,
-			Ports: []v1.ServicePort{
-				{
-					Name:     "client",
-					Port:     NACOS_PORT,
-					Protocol: "TCP",
-				},
-				{
-					Name:     "rpc",
-					Port:     RAFT_PORT,
-					Protocol: "TCP",
-				},
-				{
-					Name:     "new-rpc",
-					Port:     NEW_RAFT_PORT,
-					Protocol: "TCP",
-				},
-			},
-			Selector: labels,
+			Ports:                    enabledServicePorts(mainServicePorts),
+			Selector:                 labels,
 		},
 	}
-	myErrors.EnsureNormal(controllerutil.SetControllerReference(nacos, svc, e.scheme))
-	return svc
+	setIPFamilies(svc, nacos, false)
+	if err := controllerutil.SetControllerReference(nacos, svc, e.scheme); err != nil {
+		return nil, err
+	}
+	return svc, nil
 }
 
-func (e *KindClient) buildClientService(nacos *nacosgroupv1alpha1.Nacos) *v1.Service {
+func (e *KindClient) buildClientService(nacos *nacosgroupv1alpha1.Nacos) (*v1.Service, error) {
 	labels := e.generateLabels(nacos.Name, NACOS)
 	labels = e.MergeLabels(nacos.Labels, labels)
 
@@ -517,51 +590,40 @@ func (e *KindClient) buildClientService(nacos *nacosgroupv1alpha1.Nacos) *v1.Ser
 		Spec: v1.ServiceSpec{
 			Type:                     v1.ServiceTypeNodePort,
 			PublishNotReadyAddresses: true,
-			Ports: []v1.ServicePort{
-				{
-					Name:     "client",
-					Port:     NACOS_PORT,
-					Protocol: "TCP",
-				},
-				{
-					Name:     "rpc",
-					Port:     9848,
-					Protocol: "TCP",
-				},
-			},
-			Selector: labels,
+			Ports:                    enabledServicePorts(clientServicePorts),
+			Selector:                 labels,
 		},
 	}
 	// client-service 默认使用 IPv4 单栈，兼容未开�?IPv6 的集�?
-	var ipf = make([]v1.IPFamily, 0)
-	ipf = append(ipf, v1.IPv4Protocol)
-	svc.Spec.IPFamilies = ipf
-	var ipPli = v1.IPFamilyPolicySingleStack
-	svc.Spec.IPFamilyPolicy = &ipPli
-	myErrors.EnsureNormal(controllerutil.SetControllerReference(nacos, svc, e.scheme))
-	return svc
+	setIPFamilies(svc, nacos, true)
+	if err := controllerutil.SetControllerReference(nacos, svc, e.scheme); err != nil {
+		return nil, err
+	}
+	return svc, nil
 }
 
-func (e *KindClient) buildStatefulset(nacos *nacosgroupv1alpha1.Nacos) *appv1.StatefulSet {
+func (e *KindClient) buildStatefulset(nacos *nacosgroupv1alpha1.Nacos) (*appv1.StatefulSet, error) {
 	// 生成label
 	labels := e.generateLabels(nacos.Name, NACOS)
 	// 合并cr中原有的label
 	labels = e.MergeLabels(nacos.Labels, labels)
 
 	// 设置默认的环境变�?
-	env := append(nacos.Spec.Env, v1.EnvVar{
+	// 复制 spec.env，后续修改不影响 CR
+	env := append(append([]v1.EnvVar{}, nacos.Spec.Env...), v1.EnvVar{
+		// 成员地址使用 Pod 域名，IPv6 地址作为成员地址无法解析
 		Name:  "PREFER_HOST_MODE",
 		Value: "hostname",
 	})
 
 	switch nacos.Spec.FunctionMode {
 	case "naming":
-		env = append(nacos.Spec.Env, v1.EnvVar{
+		env = append(env, v1.EnvVar{
 			Name:  "FUNCTION_MODE",
 			Value: "naming",
 		})
 	case "config":
-		env = append(nacos.Spec.Env, v1.EnvVar{
+		env = append(env, v1.EnvVar{
 			Name:  "FUNCTION_MODE",
 			Value: "config",
 		})
@@ -638,10 +700,14 @@ func (e *KindClient) buildStatefulset(nacos *nacosgroupv1alpha1.Nacos) *appv1.St
 	} else {
 		env = append(env, v1.EnvVar{
 			Name:  "NACOS_REPLICAS",
-			Value: strconv.Itoa(int(*nacos.Spec.Replicas)),
+			Value: strconv.Itoa(int(clusterReplicas(nacos))),
 		})
 	}
 
+	env = networkEnv(nacos, env)
+	liveness, readiness, startup := probes(nacos)
+
+	replicas := statefulSetReplicas(nacos)
 	var ss = &appv1.StatefulSet{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:        e.generateName(nacos),
@@ -651,7 +717,7 @@ func (e *KindClient) buildStatefulset(nacos *nacosgroupv1alpha1.Nacos) *appv1.St
 		},
 		Spec: appv1.StatefulSetSpec{
 			PodManagementPolicy: "Parallel",
-			Replicas:            nacos.Spec.Replicas,
+			Replicas:            &replicas,
 			Selector:            &metav1.LabelSelector{MatchLabels: labels},
 			Template: v1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
@@ -685,8 +751,9 @@ func (e *KindClient) buildStatefulset(nacos *nacosgroupv1alpha1.Nacos) *appv1.St
 								},
 							},
 							Env:            env,
-							LivenessProbe:  nacos.Spec.LivenessProbe,
-							ReadinessProbe: nacos.Spec.ReadinessProbe,
+							LivenessProbe:  liveness,
+							ReadinessProbe: readiness,
+							StartupProbe:   startup,
 							VolumeMounts:   []v1.VolumeMount{},
 							Resources:      nacos.Spec.Resources,
 						},
@@ -715,49 +782,13 @@ func (e *KindClient) buildStatefulset(nacos *nacosgroupv1alpha1.Nacos) *appv1.St
 			Name: "db", MountPath: "/home/nacos/data",
 		})
 	}
-	if nacos.Spec.Volume.VolumeClaimTemplate != nil && nacos.Spec.Volume.HostPath == nil && nacos.Spec.Volume.EmptyDir == nil {
-		pvc := *nacos.Spec.Volume.VolumeClaimTemplate
-		if pvc.Name == "" {
-			pvc.Name = "db"
-		}
-		if len(pvc.Spec.AccessModes) == 0 {
-			pvc.Spec.AccessModes = []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce}
-		}
-		if nacos.Spec.Volume.PersistentVolumeSize != "" {
-			if pvc.Spec.Resources.Requests == nil {
-				pvc.Spec.Resources.Requests = v1.ResourceList{}
-			}
-			pvc.Spec.Resources.Requests[v1.ResourceStorage] = resource.MustParse(nacos.Spec.Volume.PersistentVolumeSize)
-		}
-		ss.Spec.VolumeClaimTemplates = append(ss.Spec.VolumeClaimTemplates, pvc)
+	if pvc := buildVolumeClaimTemplate(nacos); pvc != nil {
+		ss.Spec.VolumeClaimTemplates = append(ss.Spec.VolumeClaimTemplates, *pvc)
 		ss.Spec.Template.Spec.Containers[0].VolumeMounts = append(ss.Spec.Template.Spec.Containers[0].VolumeMounts, v1.VolumeMount{
 			Name: pvc.Name, MountPath: "/home/nacos/data",
 		})
 	}
 
-	//probe := &v1.Probe{
-	//	InitialDelaySeconds: 10,
-	//	PeriodSeconds:       5,
-	//	TimeoutSeconds:      4,
-	//	FailureThreshold:    5,
-	//	Handler: v1.Handler{
-	//		HTTPGet: &v1.HTTPGetAction{
-	//			Port: intstr.IntOrString{IntVal: NACOS_PORT},
-	//			Path: "/nacos/actuator/health/",
-	//		},
-	//		//TCPSocket: &v1.TCPSocketAction{
-	//		//	Port: intstr.IntOrString{IntVal: NACOS_PORT},
-	//		//},
-	//	},
-	//}
-
-	//if nacos.Spec.LivenessProbe == nil {
-	//	ss.Spec.Template.Spec.Containers[0].LivenessProbe = probe
-	//}
-	//if nacos.Spec.ReadinessProbe == nil {
-	//	ss.Spec.Template.Spec.Containers[0].ReadinessProbe = probe
-	//}
-
 	// 新的配置管理方式：挂载 final-config
 	if nacos.Spec.UserConfigRef != nil || nacos.Spec.InternalConfigRef != nil {
 		finalConfigName := nacos.Spec.FinalConfigName
@@ -816,12 +847,14 @@ func (e *KindClient) buildStatefulset(nacos *nacosgroupv1alpha1.Nacos) *appv1.St
 		}
 	}
 
-	myErrors.EnsureNormal(controllerutil.SetControllerReference(nacos, ss, e.scheme))
+	if err := controllerutil.SetControllerReference(nacos, ss, e.scheme); err != nil {
+		return nil, err
+	}
 
 	if nacos.Spec.Database.TypeDatabase == "mysql" && nacos.Spec.MysqlInitImage != "" {
 		ss = e.AddCheckDatabase(nacos, ss)
 	}
-	return ss
+	return ss, nil
 }
 
 func (e *KindClient) AddCheckDatabase(nacos *nacosgroupv1alpha1.Nacos, sts *appv1.StatefulSet) *appv1.StatefulSet {
@@ -860,7 +893,7 @@ func (e *KindClient) AddCheckDatabase(nacos *nacosgroupv1alpha1.Nacos, sts *appv
 	return sts
 }
 
-func (e *KindClient) buildConfigMap(nacos *nacosgroupv1alpha1.Nacos) *v1.ConfigMap {
+func (e *KindClient) buildConfigMap(nacos *nacosgroupv1alpha1.Nacos) (*v1.ConfigMap, error) {
 	labels := e.generateLabels(nacos.Name, NACOS)
 	labels = e.MergeLabels(nacos.Labels, labels)
 	data := make(map[string]string)
@@ -876,12 +909,22 @@ func (e *KindClient) buildConfigMap(nacos *nacosgroupv1alpha1.Nacos) *v1.ConfigM
 		},
 		Data: data,
 	}
-	myErrors.EnsureNormal(controllerutil.SetControllerReference(nacos, &cm, e.scheme))
-	return &cm
+	if err := controllerutil.SetControllerReference(nacos, &cm, e.scheme); err != nil {
+		return nil, err
+	}
+	return &cm, nil
+}
+
+// configRefError 引用的 ConfigMap 不存在时返回参数错误，其余读取失败可能是暂时的，返回普通错误以便重试
+func configRefError(ref, name string, err error) error {
+	if k8sErrors.IsNotFound(err) {
+		return myErrors.New(myErrors.CODE_PARAMETER_ERROR, "Failed to get %s ConfigMap %s: %v", ref, name, err)
+	}
+	return fmt.Errorf("failed to get %s ConfigMap %s: %w", ref, name, err)
 }
 
 // buildMergedConfigMap 合并 user-config 和 internal-config 创建 final-config
-func (e *KindClient) buildMergedConfigMap(nacos *nacosgroupv1alpha1.Nacos) *v1.ConfigMap {
+func (e *KindClient) buildMergedConfigMap(nacos *nacosgroupv1alpha1.Nacos) (*v1.ConfigMap, error) {
 	labels := e.generateLabels(nacos.Name, NACOS)
 	labels = e.MergeLabels(nacos.Labels, labels)
 
@@ -891,7 +934,7 @@ func (e *KindClient) buildMergedConfigMap(nacos *nacosgroupv1alpha1.Nacos) *v1.C
 		internalCM, err := e.k8sService.GetConfigMap(nacos.Namespace, nacos.Spec.InternalConfigRef.Name)
 		if err != nil {
 			e.logger.Error(err, "Failed to get internal-config ConfigMap", "name", nacos.Spec.InternalConfigRef.Name)
-			panic(myErrors.New(myErrors.CODE_PARAMETER_ERROR, "Failed to get internal-config ConfigMap %s: %v", nacos.Spec.InternalConfigRef.Name, err))
+			return nil, configRefError("internal-config", nacos.Spec.InternalConfigRef.Name, err)
 		}
 
 		key := nacos.Spec.InternalConfigRef.Key
@@ -903,7 +946,7 @@ func (e *KindClient) buildMergedConfigMap(nacos *nacosgroupv1alpha1.Nacos) *v1.C
 			internalContent = content
 		} else {
 			e.logger.Error(nil, "Internal-config key not found", "key", key)
-			panic(myErrors.New(myErrors.CODE_PARAMETER_ERROR, "Internal-config key not found: %s", key))
+			return nil, myErrors.New(myErrors.CODE_PARAMETER_ERROR, "Internal-config key not found: %s", key)
 		}
 	}
 
@@ -913,7 +956,7 @@ func (e *KindClient) buildMergedConfigMap(nacos *nacosgroupv1alpha1.Nacos) *v1.C
 		userCM, err := e.k8sService.GetConfigMap(nacos.Namespace, nacos.Spec.UserConfigRef.Name)
 		if err != nil {
 			e.logger.Error(err, "Failed to get user-config ConfigMap", "name", nacos.Spec.UserConfigRef.Name)
-			panic(myErrors.New(myErrors.CODE_PARAMETER_ERROR, "Failed to get user-config ConfigMap %s: %v", nacos.Spec.UserConfigRef.Name, err))
+			return nil, configRefError("user-config", nacos.Spec.UserConfigRef.Name, err)
 		}
 
 		key := nacos.Spec.UserConfigRef.Key
@@ -925,7 +968,7 @@ func (e *KindClient) buildMergedConfigMap(nacos *nacosgroupv1alpha1.Nacos) *v1.C
 			userContent = content
 		} else {
 			e.logger.Error(nil, "User-config key not found", "key", key)
-			panic(myErrors.New(myErrors.CODE_PARAMETER_ERROR, "User-config key not found: %s", key))
+			return nil, myErrors.New(myErrors.CODE_PARAMETER_ERROR, "User-config key not found: %s", key)
 		}
 	}
 
@@ -961,11 +1004,13 @@ func (e *KindClient) buildMergedConfigMap(nacos *nacosgroupv1alpha1.Nacos) *v1.C
 		},
 		Data: data,
 	}
-	myErrors.EnsureNormal(controllerutil.SetControllerReference(nacos, &cm, e.scheme))
-	return &cm
+	if err := controllerutil.SetControllerReference(nacos, &cm, e.scheme); err != nil {
+		return nil, err
+	}
+	return &cm, nil
 }
 
-func (e *KindClient) buildDefaultConfigMap(nacos *nacosgroupv1alpha1.Nacos) *v1.ConfigMap {
+func (e *KindClient) buildDefaultConfigMap(nacos *nacosgroupv1alpha1.Nacos) (*v1.ConfigMap, error) {
 	labels := e.generateLabels(nacos.Name, NACOS)
 	labels = e.MergeLabels(nacos.Labels, labels)
 	data := make(map[string]string)
@@ -1027,8 +1072,10 @@ func (e *KindClient) buildDefaultConfigMap(nacos *nacosgroupv1alpha1.Nacos) *v1.
 		},
 		Data: data,
 	}
-	myErrors.EnsureNormal(controllerutil.SetControllerReference(nacos, &cm, e.scheme))
-	return &cm
+	if err := controllerutil.SetControllerReference(nacos, &cm, e.scheme); err != nil {
+		return nil, err
+	}
+	return &cm, nil
 }
 
 func (e *KindClient) buildStatefulsetCluster(nacos *nacosgroupv1alpha1.Nacos, ss *appv1.StatefulSet) *appv1.StatefulSet {
@@ -1041,9 +1088,15 @@ func (e *KindClient) buildStatefulsetCluster(nacos *nacosgroupv1alpha1.Nacos, ss
 		}
 	}
 	ss.Spec.ServiceName = e.generateHeadlessSvcName(nacos)
+	// 分批升级时只更新序号不小于 partition 的成员
+	partition := upgradePartition(nacos)
+	ss.Spec.UpdateStrategy = appv1.StatefulSetUpdateStrategy{
+		Type:          appv1.RollingUpdateStatefulSetStrategyType,
+		RollingUpdate: &appv1.RollingUpdateStatefulSetStrategy{Partition: &partition},
+	}
 	serivce := ""
 	serivceNoPort := ""
-	for i := 0; i < int(*nacos.Spec.Replicas); i++ {
+	for _, i := range clusterOrdinals(nacos) {
 		serivce = fmt.Sprintf("%v%v-%d.%v.%v.%v.%v:%v ", serivce, e.generateName(nacos), i, e.generateHeadlessSvcName(nacos), nacos.Namespace, "svc", domain, NACOS_PORT)
 		serivceNoPort = fmt.Sprintf("%v%v-%d.%v.%v.%v.%v ", serivceNoPort, e.generateName(nacos), i, e.generateHeadlessSvcName(nacos), nacos.Namespace, "svc", domain)
 	}
@@ -1055,6 +1108,10 @@ func (e *KindClient) buildStatefulsetCluster(nacos *nacosgroupv1alpha1.Nacos, ss
 		},
 	}
 	ss.Spec.Template.Spec.Containers[0].Env = append(ss.Spec.Template.Spec.Containers[0].Env, env...)
+	// 灾难恢复过程中只有恢复成员启动 Nacos
+	if recovering(nacos) {
+		ss.Spec.Template.Spec.InitContainers = append(ss.Spec.Template.Spec.InitContainers, buildRecoveryInitContainer(nacos, ss))
+	}
 	// fix by yrc10943，去掉前置网络检查，避免灾难恢复场景单节点无法恢复整个集群无法恢复
 	ss.Spec.Template.Spec.Containers[0].Command = []string{"/bin/bash", "-c", "bin/docker-startup.sh"}
 	return ss
@@ -1063,11 +1120,89 @@ func (e *KindClient) buildStatefulsetCluster(nacos *nacosgroupv1alpha1.Nacos, ss
 func (e *KindClient) buildHeadlessServiceCluster(svc *v1.Service, nacos *nacosgroupv1alpha1.Nacos) *v1.Service {
 	svc.Spec.ClusterIP = "None"
 	svc.Name = e.generateHeadlessSvcName(nacos)
-	//nacos pod间raft 探测交互走ipv4
-	var ipf = make([]v1.IPFamily, 0)
-	ipf = append(ipf, v1.IPv4Protocol)
-	svc.Spec.IPFamilies = ipf
-	var ipPli = v1.IPFamilyPolicySingleStack
-	svc.Spec.IPFamilyPolicy = &ipPli
+	//nacos pod间raft 探测交互默认走ipv4
+	setIPFamilies(svc, nacos, true)
 	return svc
 }
+
+// ScaleDownForDeletion 删除 CR 前按序号从大到小逐个缩容 StatefulSet，缩容到 0 后返回 true
+func (e *KindClient) ScaleDownForDeletion(nacos *nacosgroupv1alpha1.Nacos) (bool, error) {
+	ss, err := e.k8sService.GetStatefulSet(nacos.Namespace, e.generateName(nacos))
+	if err != nil {
+		if k8sErrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	}
+	replicas := int32(0)
+	if ss.Spec.Replicas != nil {
+		replicas = *ss.Spec.Replicas
+	}
+	// 上一个缩容的 Pod 还未退出
+	if ss.Status.Replicas > replicas {
+		return false, nil
+	}
+	if replicas == 0 {
+		return true, nil
+	}
+	replicas--
+	ss.Spec.Replicas = &replicas
+	if err := e.k8sService.UpdateStatefulSet(nacos.Namespace, ss); err != nil {
+		return false, err
+	}
+	e.logger.Info("scale down statefulset for deletion", "name", ss.Name, "replicas", replicas)
+	e.recorder.Eventf(nacos, v1.EventTypeNormal, ReasonScaled, "scale statefulset %s down to %d for deletion", ss.Name, replicas)
+	return false, nil
+}
+
+// generateVolumeClaimPrefix 返回 volumeClaimTemplates 生成的 PVC 名称前缀，未使用 PVC 时返回空
+func (e *KindClient) generateVolumeClaimPrefix(nacos *nacosgroupv1alpha1.Nacos) string {
+	if nacos.Spec.Volume.VolumeClaimTemplate == nil || nacos.Spec.Volume.HostPath != nil || nacos.Spec.Volume.EmptyDir != nil {
+		return ""
+	}
+	claimName := nacos.Spec.Volume.VolumeClaimTemplate.Name
+	if claimName == "" {
+		claimName = "db"
+	}
+	return fmt.Sprintf("%s-%s-", claimName, e.generateName(nacos))
+}
+
+// listVolumeClaims 返回 StatefulSet volumeClaimTemplates 创建的 PVC，包括缩容后保留的 PVC
+func (e *KindClient) listVolumeClaims(nacos *nacosgroupv1alpha1.Nacos) ([]v1.PersistentVolumeClaim, error) {
+	prefix := e.generateVolumeClaimPrefix(nacos)
+	if prefix == "" {
+		return nil, nil
+	}
+	pvcs, err := e.k8sService.ListPersistentVolumeClaims(nacos.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	var claims []v1.PersistentVolumeClaim
+	for _, pvc := range pvcs.Items {
+		ordinal := strings.TrimPrefix(pvc.Name, prefix)
+		if ordinal == pvc.Name {
+			continue
+		}
+		if _, err := strconv.Atoi(ordinal); err != nil {
+			continue
+		}
+		claims = append(claims, pvc)
+	}
+	return claims, nil
+}
+
+// DeleteVolumeClaims 删除 StatefulSet volumeClaimTemplates 创建的 PVC，返回删除的数量
+func (e *KindClient) DeleteVolumeClaims(nacos *nacosgroupv1alpha1.Nacos) (int, error) {
+	pvcs, err := e.listVolumeClaims(nacos)
+	if err != nil {
+		return 0, err
+	}
+	deleted := 0
+	for _, pvc := range pvcs {
+		if err := e.k8sService.DeletePersistentVolumeClaim(nacos.Namespace, pvc.Name); err != nil && !k8sErrors.IsNotFound(err) {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
@@ -1,14 +1,18 @@
 package operator
 
 import (
+	"context"
 	"testing"
 
 	"github.com/go-logr/logr"
 	v1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
 	nacosgroupv1alpha1 "nacos.io/nacos-operator/api/v1alpha1"
+	myErrors "nacos.io/nacos-operator/pkg/errors"
 	"nacos.io/nacos-operator/pkg/service/k8s"
 )
 
@@ -18,12 +22,16 @@ func TestBuildMergedConfigMap(t *testing.T) {
 	_ = v1.AddToScheme(scheme)
 
 	tests := []struct {
-		name               string
-		nacos              *nacosgroupv1alpha1.Nacos
-		internalConfigMap  *v1.ConfigMap
-		userConfigMap      *v1.ConfigMap
-		expectedContent    string
-		expectPanic        bool
+		name              string
+		nacos             *nacosgroupv1alpha1.Nacos
+		internalConfigMap *v1.ConfigMap
+		userConfigMap     *v1.ConfigMap
+		expectedContent   string
+		expectErr         bool
+		// 读取 ConfigMap 时返回的错误
+		getErr error
+		// 错误是否为不再重试的参数错误
+		terminal bool
 	}{
 		{
 			name: "merge internal and user config",
@@ -63,7 +71,7 @@ func TestBuildMergedConfigMap(t *testing.T) {
 				},
 			},
 			expectedContent: "# ===== Internal Configuration =====\nserver.port=8848\ndb.type=embedded\n\n# ===== User Configuration =====\ncustom.property=value\n",
-			expectPanic:     false,
+			expectErr:       false,
 		},
 		{
 			name: "only internal config",
@@ -89,7 +97,7 @@ func TestBuildMergedConfigMap(t *testing.T) {
 				},
 			},
 			expectedContent: "# ===== Internal Configuration =====\nserver.port=8848\n\n",
-			expectPanic:     false,
+			expectErr:       false,
 		},
 		{
 			name: "only user config",
@@ -115,7 +123,7 @@ func TestBuildMergedConfigMap(t *testing.T) {
 				},
 			},
 			expectedContent: "# ===== User Configuration =====\ncustom.property=value\n",
-			expectPanic:     false,
+			expectErr:       false,
 		},
 		{
 			name: "custom final config name",
@@ -142,7 +150,42 @@ func TestBuildMergedConfigMap(t *testing.T) {
 				},
 			},
 			expectedContent: "# ===== User Configuration =====\ntest=value\n",
-			expectPanic:     false,
+			expectErr:       false,
+		},
+		{
+			name: "missing user config",
+			nacos: &nacosgroupv1alpha1.Nacos{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-nacos",
+					Namespace: "default",
+					UID:       "test-uid",
+				},
+				Spec: nacosgroupv1alpha1.NacosSpec{
+					UserConfigRef: &nacosgroupv1alpha1.ConfigMapRef{
+						Name: "user-config",
+					},
+				},
+			},
+			expectErr: true,
+			terminal:  true,
+		},
+		{
+			name: "transient error reading user config",
+			nacos: &nacosgroupv1alpha1.Nacos{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-nacos",
+					Namespace: "default",
+					UID:       "test-uid",
+				},
+				Spec: nacosgroupv1alpha1.NacosSpec{
+					UserConfigRef: &nacosgroupv1alpha1.ConfigMapRef{
+						Name: "user-config",
+					},
+				},
+			},
+			getErr:    k8sErrors.NewServiceUnavailable("etcdserver: request timed out"),
+			expectErr: true,
+			terminal:  false,
 		},
 	}
 
@@ -164,26 +207,33 @@ func TestBuildMergedConfigMap(t *testing.T) {
 				}
 			}
 
-			k8sService := k8s.NewK8sService(fakeClient, logr.Discard())
+			if tt.getErr != nil {
+				fakeClient.PrependReactor("get", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+					return true, nil, tt.getErr
+				})
+			}
+
+			k8sService := k8s.NewK8sService(fakeClient, nil, logr.Discard())
 			kindClient := &KindClient{
 				k8sService: k8sService,
 				scheme:     scheme,
 				logger:     logr.Discard(),
 			}
 
-			if tt.expectPanic {
-				defer func() {
-					if r := recover(); r == nil {
-						t.Errorf("Expected panic but did not panic")
-					}
-				}()
-			}
-
-			result := kindClient.buildMergedConfigMap(tt.nacos)
+			result, err := kindClient.buildMergedConfigMap(tt.nacos)
 
-			if tt.expectPanic {
+			if tt.expectErr {
+				if err == nil {
+					t.Errorf("Expected error but got nil")
+				}
+				if myErr, ok := err.(*myErrors.Err); tt.terminal != (ok && myErr.Code == myErrors.CODE_PARAMETER_ERROR) {
+					t.Errorf("Expected terminal=%v, got %v", tt.terminal, err)
+				}
 				return
 			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
 
 			expectedName := tt.nacos.Spec.FinalConfigName
 			if expectedName == "" {
@@ -204,3 +254,39 @@ func TestBuildMergedConfigMap(t *testing.T) {
 		})
 	}
 }
+
+func TestDeleteVolumeClaims(t *testing.T) {
+	nacos := &nacosgroupv1alpha1.Nacos{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-nacos", Namespace: "default"},
+		Spec: nacosgroupv1alpha1.NacosSpec{
+			Volume: nacosgroupv1alpha1.Storage{
+				VolumeClaimTemplate: &v1.PersistentVolumeClaim{},
+			},
+		},
+	}
+	pvc := func(name string) *v1.PersistentVolumeClaim {
+		return &v1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}}
+	}
+	fakeClient := fake.NewSimpleClientset(
+		pvc("db-test-nacos-0"),
+		pvc("db-test-nacos-1"),
+		pvc("db-test-nacos-other-0"),
+		pvc("db-other-0"),
+	)
+	kindClient := &KindClient{
+		k8sService: k8s.NewK8sService(fakeClient, nil, logr.Discard()),
+		logger:     logr.Discard(),
+	}
+
+	deleted, err := kindClient.DeleteVolumeClaims(nacos)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("Expected 2 pvc deleted, got %d", deleted)
+	}
+	left, _ := fakeClient.CoreV1().PersistentVolumeClaims("default").List(context.TODO(), metav1.ListOptions{})
+	if len(left.Items) != 2 {
+		t.Errorf("Expected 2 pvc left, got %d", len(left.Items))
+	}
+}
@@ -0,0 +1,47 @@
+package operator
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	nacosgroupv1alpha1 "nacos.io/nacos-operator/api/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// operator 暴露的指标，注册到 controller-runtime 的 /metrics
+var (
+	canarySuccess = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "nacos_operator_canary_success",
+		Help: "Whether the last canary check of a Nacos cluster succeeded (1) or failed (0).",
+	}, []string{"namespace", "name", "check"})
+	canaryLatency = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "nacos_operator_canary_latency_seconds",
+		Help: "Latency of the last canary check of a Nacos cluster.",
+	}, []string{"namespace", "name", "check"})
+	canaryFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "nacos_operator_canary_failures_total",
+		Help: "Number of failed canary checks of a Nacos cluster.",
+	}, []string{"namespace", "name", "check"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(canarySuccess, canaryLatency, canaryFailures)
+}
+
+func observeCanary(nacos *nacosgroupv1alpha1.Nacos, check string, result nacosgroupv1alpha1.CanaryResult) {
+	success := 0.0
+	if result.Success {
+		success = 1
+	} else {
+		canaryFailures.WithLabelValues(nacos.Namespace, nacos.Name, check).Inc()
+	}
+	canarySuccess.WithLabelValues(nacos.Namespace, nacos.Name, check).Set(success)
+	canaryLatency.WithLabelValues(nacos.Namespace, nacos.Name, check).Set(float64(result.LatencyMilliseconds) / 1000)
+}
+
+// deleteMetrics 删除 CR 时清理它的指标
+func deleteMetrics(nacos *nacosgroupv1alpha1.Nacos) {
+	for _, check := range []string{CanaryConfig, CanaryNaming} {
+		canarySuccess.DeleteLabelValues(nacos.Namespace, nacos.Name, check)
+		canaryLatency.DeleteLabelValues(nacos.Namespace, nacos.Name, check)
+		canaryFailures.DeleteLabelValues(nacos.Namespace, nacos.Name, check)
+	}
+}
@@ -0,0 +1,86 @@
+package operator
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+
+	nacosgroupv1alpha1 "nacos.io/nacos-operator/api/v1alpha1"
+	myErrors "nacos.io/nacos-operator/pkg/errors"
+)
+
+const preferIPv6Option = "-Djava.net.preferIPv6Addresses=true"
+
+// validateNetwork 检查 spec.network，协议族重复或与 policy 冲突时 apiserver 会拒绝所有 Service
+func validateNetwork(nacos *nacosgroupv1alpha1.Nacos) error {
+	network := nacos.Spec.Network
+	families := network.IPFamilies
+	if len(families) > 2 || len(families) == 2 && families[0] == families[1] {
+		return myErrors.New(myErrors.CODE_PARAMETER_ERROR, myErrors.MSG_PARAMETER_ERROT, "nacos.Spec.Network.ipFamilies", families)
+	}
+	for _, family := range families {
+		if family != v1.IPv4Protocol && family != v1.IPv6Protocol {
+			return myErrors.New(myErrors.CODE_PARAMETER_ERROR, myErrors.MSG_PARAMETER_ERROT, "nacos.Spec.Network.ipFamilies", families)
+		}
+	}
+	if policy := network.IPFamilyPolicy; policy != nil {
+		switch *policy {
+		case v1.IPFamilyPolicySingleStack:
+			if len(families) > 1 {
+				return myErrors.New(myErrors.CODE_PARAMETER_ERROR, myErrors.MSG_PARAMETER_ERROT, "nacos.Spec.Network.ipFamilyPolicy", fmt.Sprintf("%s with %v", *policy, families))
+			}
+		case v1.IPFamilyPolicyPreferDualStack, v1.IPFamilyPolicyRequireDualStack:
+		default:
+			return myErrors.New(myErrors.CODE_PARAMETER_ERROR, myErrors.MSG_PARAMETER_ERROT, "nacos.Spec.Network.ipFamilyPolicy", *policy)
+		}
+	}
+	return nil
+}
+
+// setIPFamilies 使用 spec.network 的协议族；未配置时 ipv4Default 为 true 的 Service 使用 IPv4 单栈，否则由集群决定
+func setIPFamilies(svc *v1.Service, nacos *nacosgroupv1alpha1.Nacos, ipv4Default bool) {
+	network := nacos.Spec.Network
+	if len(network.IPFamilies) == 0 && network.IPFamilyPolicy == nil {
+		if ipv4Default {
+			policy := v1.IPFamilyPolicySingleStack
+			svc.Spec.IPFamilies = []v1.IPFamily{v1.IPv4Protocol}
+			svc.Spec.IPFamilyPolicy = &policy
+		}
+		return
+	}
+	svc.Spec.IPFamilies = append([]v1.IPFamily{}, network.IPFamilies...)
+	policy := v1.IPFamilyPolicySingleStack
+	switch {
+	case network.IPFamilyPolicy != nil:
+		policy = *network.IPFamilyPolicy
+	case len(network.IPFamilies) > 1:
+		policy = v1.IPFamilyPolicyRequireDualStack
+	}
+	svc.Spec.IPFamilyPolicy = &policy
+}
+
+// preferIPv6 主协议族为 IPv6
+func preferIPv6(nacos *nacosgroupv1alpha1.Nacos) bool {
+	families := nacos.Spec.Network.IPFamilies
+	return len(families) > 0 && families[0] == v1.IPv6Protocol
+}
+
+// networkEnv 主协议族为 IPv6 时在 JAVA_OPT_EXT 中追加 preferIPv6Addresses，
+// 使 Nacos 绑定与上报 IPv6 地址；保留 spec.env 中已有的 JAVA_OPT_EXT
+func networkEnv(nacos *nacosgroupv1alpha1.Nacos, env []v1.EnvVar) []v1.EnvVar {
+	if !preferIPv6(nacos) {
+		return env
+	}
+	for i := range env {
+		if env[i].Name != "JAVA_OPT_EXT" {
+			continue
+		}
+		// 引用 ConfigMap/Secret 的由用户自行配置
+		if env[i].ValueFrom == nil && !strings.Contains(env[i].Value, "java.net.preferIPv6Addresses") {
+			env[i].Value = strings.TrimSpace(env[i].Value + " " + preferIPv6Option)
+		}
+		return env
+	}
+	return append(env, v1.EnvVar{Name: "JAVA_OPT_EXT", Value: preferIPv6Option})
+}
@@ -0,0 +1,100 @@
+package operator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	nacosgroupv1alpha1 "nacos.io/nacos-operator/api/v1alpha1"
+	myErrors "nacos.io/nacos-operator/pkg/errors"
+	"nacos.io/nacos-operator/pkg/service/k8s"
+)
+
+func TestValidateNetwork(t *testing.T) {
+	singleStack := v1.IPFamilyPolicySingleStack
+	tests := []struct {
+		name    string
+		network nacosgroupv1alpha1.NetworkSpec
+		valid   bool
+	}{
+		{name: "empty", valid: true},
+		{name: "ipv6 only", network: nacosgroupv1alpha1.NetworkSpec{IPFamilies: []v1.IPFamily{v1.IPv6Protocol}}, valid: true},
+		{name: "dual stack", network: nacosgroupv1alpha1.NetworkSpec{IPFamilies: []v1.IPFamily{v1.IPv6Protocol, v1.IPv4Protocol}}, valid: true},
+		{name: "duplicated", network: nacosgroupv1alpha1.NetworkSpec{IPFamilies: []v1.IPFamily{v1.IPv4Protocol, v1.IPv4Protocol}}},
+		{name: "unknown family", network: nacosgroupv1alpha1.NetworkSpec{IPFamilies: []v1.IPFamily{"IPv5"}}},
+		{name: "single stack with two families", network: nacosgroupv1alpha1.NetworkSpec{
+			IPFamilies: []v1.IPFamily{v1.IPv4Protocol, v1.IPv6Protocol}, IPFamilyPolicy: &singleStack,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nacos := &nacosgroupv1alpha1.Nacos{Spec: nacosgroupv1alpha1.NacosSpec{Network: tt.network}}
+			err := validateNetwork(nacos)
+			if tt.valid && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if myErr, ok := err.(*myErrors.Err); !tt.valid && (!ok || myErr.Code != myErrors.CODE_PARAMETER_ERROR) {
+				t.Errorf("Expected parameter error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestNetworkDrivesServicesAndJVM(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = nacosgroupv1alpha1.AddToScheme(scheme)
+	fakeClient := fake.NewSimpleClientset()
+	kindClient := &KindClient{
+		k8sService: k8s.NewK8sService(fakeClient, nil, logr.Discard()),
+		logger:     logr.Discard(),
+		scheme:     scheme,
+	}
+	replicas := int32(3)
+	nacos := &nacosgroupv1alpha1.Nacos{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-nacos", Namespace: "default", UID: "test-uid"},
+		Spec: nacosgroupv1alpha1.NacosSpec{
+			Type:         TYPE_CLUSTER,
+			Replicas:     &replicas,
+			FunctionMode: "naming",
+			Env:          []v1.EnvVar{{Name: "JAVA_OPT_EXT", Value: "-Xss512k"}},
+			Network:      nacosgroupv1alpha1.NetworkSpec{IPFamilies: []v1.IPFamily{v1.IPv6Protocol, v1.IPv4Protocol}},
+		},
+	}
+
+	for _, ensure := range []func(*nacosgroupv1alpha1.Nacos) error{kindClient.EnsureHeadlessServiceCluster, kindClient.EnsureClientService} {
+		if err := ensure(nacos); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	for _, name := range []string{"test-nacos-headless", "test-nacos-client"} {
+		svc, err := fakeClient.CoreV1().Services("default").Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Expected service %s: %v", name, err)
+		}
+		if len(svc.Spec.IPFamilies) != 2 || svc.Spec.IPFamilies[0] != v1.IPv6Protocol || *svc.Spec.IPFamilyPolicy != v1.IPFamilyPolicyRequireDualStack {
+			t.Errorf("Expected %s dual stack with IPv6 first, got %v %v", name, svc.Spec.IPFamilies, *svc.Spec.IPFamilyPolicy)
+		}
+	}
+
+	ss, err := kindClient.buildStatefulset(nacos)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	env := map[string]string{}
+	for _, e := range ss.Spec.Template.Spec.Containers[0].Env {
+		env[e.Name] = e.Value
+	}
+	if env["PREFER_HOST_MODE"] != "hostname" || env["FUNCTION_MODE"] != "naming" {
+		t.Errorf("Expected hostname mode kept with function mode, got %v", env)
+	}
+	if env["JAVA_OPT_EXT"] != "-Xss512k "+preferIPv6Option {
+		t.Errorf("Expected preferIPv6Addresses appended, got %q", env["JAVA_OPT_EXT"])
+	}
+	if nacos.Spec.Env[0].Value != "-Xss512k" {
+		t.Errorf("Expected spec.env untouched, got %q", nacos.Spec.Env[0].Value)
+	}
+}
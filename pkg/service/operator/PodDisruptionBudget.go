@@ -0,0 +1,111 @@
+package operator
+
+import (
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	nacosgroupv1alpha1 "nacos.io/nacos-operator/api/v1alpha1"
+	myErrors "nacos.io/nacos-operator/pkg/errors"
+)
+
+// EnsurePodDisruptionBudget 集群模式下创建或更新 PodDisruptionBudget，副本数变化时重新计算；
+// 关闭后删除由该 CR 创建的 PodDisruptionBudget
+func (e *KindClient) EnsurePodDisruptionBudget(nacos *nacosgroupv1alpha1.Nacos) error {
+	name := e.generateName(nacos)
+	if nacos.Spec.Type != TYPE_CLUSTER || nacos.Spec.PodDisruptionBudget.Disabled {
+		return e.deleteOwnedPodDisruptionBudget(nacos, name)
+	}
+
+	desired, err := e.buildPodDisruptionBudget(nacos)
+	if err != nil {
+		return err
+	}
+	current, err := e.k8sService.GetPodDisruptionBudget(nacos.Namespace, name)
+	if err != nil {
+		if k8sErrors.IsNotFound(err) {
+			return e.k8sService.CreatePodDisruptionBudget(nacos.Namespace, desired)
+		}
+		return err
+	}
+	repaired, changed := repairPodDisruptionBudget(current, desired)
+	if !changed {
+		return nil
+	}
+	e.logger.Info("update poddisruptionbudget", "name", name, "maxUnavailable", desired.Spec.MaxUnavailable, "minAvailable", desired.Spec.MinAvailable)
+	return e.k8sService.UpdatePodDisruptionBudget(nacos.Namespace, repaired)
+}
+
+func (e *KindClient) deleteOwnedPodDisruptionBudget(nacos *nacosgroupv1alpha1.Nacos, name string) error {
+	current, err := e.k8sService.GetPodDisruptionBudget(nacos.Namespace, name)
+	if err != nil {
+		if k8sErrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if !ownedBy(current, nacos) {
+		return nil
+	}
+	e.logger.Info("delete poddisruptionbudget", "name", name)
+	return e.k8sService.DeletePodDisruptionBudget(nacos.Namespace, name)
+}
+
+func (e *KindClient) buildPodDisruptionBudget(nacos *nacosgroupv1alpha1.Nacos) (*policyv1.PodDisruptionBudget, error) {
+	spec := nacos.Spec.PodDisruptionBudget
+	if spec.MaxUnavailable != nil && spec.MinAvailable != nil {
+		return nil, myErrors.New(myErrors.CODE_PARAMETER_ERROR, myErrors.MSG_PARAMETER_ERROT, "nacos.Spec.PodDisruptionBudget", "both maxUnavailable and minAvailable")
+	}
+
+	labels := e.generateLabels(nacos.Name, NACOS)
+	labels = e.MergeLabels(nacos.Labels, labels)
+
+	pdb := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        e.generateName(nacos),
+			Namespace:   nacos.Namespace,
+			Labels:      labels,
+			Annotations: e.MergeLabels(e.generateAnnoation(), nacos.Annotations),
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+		},
+	}
+	switch {
+	case spec.MinAvailable != nil:
+		minAvailable := *spec.MinAvailable
+		pdb.Spec.MinAvailable = &minAvailable
+	case spec.MaxUnavailable != nil:
+		maxUnavailable := *spec.MaxUnavailable
+		pdb.Spec.MaxUnavailable = &maxUnavailable
+	default:
+		maxUnavailable := intstr.FromInt(int(quorumMaxUnavailable(clusterReplicas(nacos))))
+		pdb.Spec.MaxUnavailable = &maxUnavailable
+	}
+	if err := controllerutil.SetControllerReference(nacos, pdb, e.scheme); err != nil {
+		return nil, err
+	}
+	return pdb, nil
+}
+
+// quorumMaxUnavailable 驱逐后仍保留 Raft 多数派（replicas/2+1）时最多可中断的 Pod 数，
+// 2 个及以下副本为 0，此时节点排空会被阻塞，需要显式设置 maxUnavailable
+func quorumMaxUnavailable(replicas int32) int32 {
+	if replicas <= 0 {
+		return 0
+	}
+	return (replicas - 1) / 2
+}
+
+// repairPodDisruptionBudget 以期望的 selector 与中断预算为准，返回修复后的对象以及是否有变化
+func repairPodDisruptionBudget(current, desired *policyv1.PodDisruptionBudget) (*policyv1.PodDisruptionBudget, bool) {
+	repaired := current.DeepCopy()
+	repairObjectMeta(&repaired.ObjectMeta, &desired.ObjectMeta)
+	repaired.Spec.Selector = desired.Spec.Selector
+	repaired.Spec.MaxUnavailable = desired.Spec.MaxUnavailable
+	repaired.Spec.MinAvailable = desired.Spec.MinAvailable
+	return repaired, !equality.Semantic.DeepEqual(current, repaired)
+}
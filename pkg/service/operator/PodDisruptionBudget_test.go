@@ -0,0 +1,86 @@
+package operator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
+	nacosgroupv1alpha1 "nacos.io/nacos-operator/api/v1alpha1"
+	myErrors "nacos.io/nacos-operator/pkg/errors"
+	"nacos.io/nacos-operator/pkg/service/k8s"
+)
+
+func TestQuorumMaxUnavailable(t *testing.T) {
+	for replicas, want := range map[int32]int32{0: 0, 1: 0, 2: 0, 3: 1, 4: 1, 5: 2, 7: 3} {
+		if got := quorumMaxUnavailable(replicas); got != want {
+			t.Errorf("replicas %d: expected %d, got %d", replicas, want, got)
+		}
+	}
+}
+
+func TestEnsurePodDisruptionBudget(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = nacosgroupv1alpha1.AddToScheme(scheme)
+	fakeClient := fake.NewSimpleClientset()
+	kindClient := &KindClient{
+		k8sService: k8s.NewK8sService(fakeClient, nil, logr.Discard()),
+		logger:     logr.Discard(),
+		scheme:     scheme,
+	}
+	replicas := int32(3)
+	nacos := &nacosgroupv1alpha1.Nacos{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-nacos", Namespace: "default", UID: "test-uid"},
+		Spec:       nacosgroupv1alpha1.NacosSpec{Type: TYPE_CLUSTER, Replicas: &replicas},
+	}
+	get := func() (*intstr.IntOrString, *intstr.IntOrString, bool) {
+		pdb, err := fakeClient.PolicyV1().PodDisruptionBudgets("default").Get(context.TODO(), "test-nacos", metav1.GetOptions{})
+		if err != nil {
+			return nil, nil, false
+		}
+		return pdb.Spec.MaxUnavailable, pdb.Spec.MinAvailable, true
+	}
+
+	if err := kindClient.EnsurePodDisruptionBudget(nacos); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if maxUnavailable, _, ok := get(); !ok || maxUnavailable.IntValue() != 1 {
+		t.Fatalf("Expected maxUnavailable 1, got %v", maxUnavailable)
+	}
+
+	// 扩容后重新计算
+	replicas = 5
+	if err := kindClient.EnsurePodDisruptionBudget(nacos); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if maxUnavailable, _, _ := get(); maxUnavailable.IntValue() != 2 {
+		t.Errorf("Expected maxUnavailable 2 after scale, got %v", maxUnavailable)
+	}
+
+	minAvailable := intstr.FromString("60%")
+	nacos.Spec.PodDisruptionBudget.MinAvailable = &minAvailable
+	if err := kindClient.EnsurePodDisruptionBudget(nacos); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if maxUnavailable, got, _ := get(); maxUnavailable != nil || got == nil || got.StrVal != "60%" {
+		t.Errorf("Expected only minAvailable 60%%, got maxUnavailable %v minAvailable %v", maxUnavailable, got)
+	}
+
+	maxUnavailable := intstr.FromInt(1)
+	nacos.Spec.PodDisruptionBudget.MaxUnavailable = &maxUnavailable
+	err := kindClient.EnsurePodDisruptionBudget(nacos)
+	if myErr, ok := err.(*myErrors.Err); !ok || myErr.Code != myErrors.CODE_PARAMETER_ERROR {
+		t.Errorf("Expected parameter error, got %v", err)
+	}
+
+	nacos.Spec.PodDisruptionBudget.Disabled = true
+	if err := kindClient.EnsurePodDisruptionBudget(nacos); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, _, ok := get(); ok {
+		t.Errorf("Expected poddisruptionbudget deleted after opt-out")
+	}
+}
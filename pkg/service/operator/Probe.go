@@ -0,0 +1,58 @@
+package operator
+
+import (
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	nacosgroupv1alpha1 "nacos.io/nacos-operator/api/v1alpha1"
+	"nacos.io/nacos-operator/pkg/util/merge"
+)
+
+const (
+	LIVENESS_PATH  = "/v1/console/health/liveness"
+	READINESS_PATH = "/v1/console/health/readiness"
+)
+
+// Derby 与 raft 日志恢复较慢，内置存储的启动探针允许约 10 分钟，MySQL 约 5 分钟
+const (
+	startupFailureThreshold         = 30
+	embeddedStartupFailureThreshold = 60
+)
+
+// healthProbe 访问 Nacos 健康检查接口的探针，端口与上下文路径跟随 spec.env/spec.config；
+// 显式写出 apiserver 的默认值，避免每次调谐都判定为变化
+func healthProbe(nacos *nacosgroupv1alpha1.Nacos, path string, periodSeconds, failureThreshold int32) *v1.Probe {
+	endpoint := nacosEndpoint(nacos)
+	scheme := v1.URISchemeHTTP
+	if nacos.Spec.TLS != nil {
+		scheme = v1.URISchemeHTTPS
+	}
+	return &v1.Probe{
+		Handler: v1.Handler{
+			HTTPGet: &v1.HTTPGetAction{
+				Path:   strings.TrimRight(endpoint.PathPrefix(), "/") + path,
+				Port:   intstr.FromInt(endpoint.Port),
+				Scheme: scheme,
+			},
+		},
+		TimeoutSeconds:   3,
+		PeriodSeconds:    periodSeconds,
+		SuccessThreshold: 1,
+		FailureThreshold: failureThreshold,
+	}
+}
+
+// probes 返回 liveness、readiness、startup 探针：默认探针由 startup 探针兜住启动时间，
+// spec 中的探针逐字段覆盖默认值
+func probes(nacos *nacosgroupv1alpha1.Nacos) (liveness, readiness, startup *v1.Probe) {
+	threshold := int32(startupFailureThreshold)
+	if nacos.Spec.Database.TypeDatabase != "mysql" {
+		threshold = embeddedStartupFailureThreshold
+	}
+	liveness = merge.Probe(healthProbe(nacos, LIVENESS_PATH, 10, 3), nacos.Spec.LivenessProbe)
+	readiness = merge.Probe(healthProbe(nacos, READINESS_PATH, 5, 3), nacos.Spec.ReadinessProbe)
+	startup = merge.Probe(healthProbe(nacos, LIVENESS_PATH, 10, threshold), nacos.Spec.StartupProbe)
+	return liveness, readiness, startup
+}
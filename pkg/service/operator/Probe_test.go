@@ -0,0 +1,53 @@
+package operator
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	nacosgroupv1alpha1 "nacos.io/nacos-operator/api/v1alpha1"
+)
+
+func TestProbes(t *testing.T) {
+	nacos := &nacosgroupv1alpha1.Nacos{
+		Spec: nacosgroupv1alpha1.NacosSpec{
+			Env: []v1.EnvVar{
+				{Name: "NACOS_APPLICATION_PORT", Value: "8849"},
+				{Name: "SERVER_SERVLET_CONTEXTPATH", Value: "/"},
+			},
+		},
+	}
+	liveness, readiness, startup := probes(nacos)
+	if liveness.HTTPGet.Path != LIVENESS_PATH || liveness.HTTPGet.Port.IntValue() != 8849 || liveness.HTTPGet.Scheme != v1.URISchemeHTTP {
+		t.Errorf("Unexpected liveness probe %+v", liveness.HTTPGet)
+	}
+	if readiness.HTTPGet.Path != READINESS_PATH {
+		t.Errorf("Unexpected readiness path %s", readiness.HTTPGet.Path)
+	}
+	if startup.HTTPGet.Path != LIVENESS_PATH || startup.FailureThreshold != embeddedStartupFailureThreshold {
+		t.Errorf("Unexpected startup probe %+v", startup)
+	}
+
+	// 默认上下文路径、TLS 与 MySQL
+	nacos.Spec.Env = nil
+	nacos.Spec.TLS = &nacosgroupv1alpha1.TLSSpec{}
+	nacos.Spec.Database.TypeDatabase = "mysql"
+	liveness, _, startup = probes(nacos)
+	if liveness.HTTPGet.Path != "/nacos"+LIVENESS_PATH || liveness.HTTPGet.Port.IntValue() != NACOS_PORT || liveness.HTTPGet.Scheme != v1.URISchemeHTTPS {
+		t.Errorf("Unexpected liveness probe %+v", liveness.HTTPGet)
+	}
+	if startup.FailureThreshold != startupFailureThreshold {
+		t.Errorf("Expected mysql startup threshold, got %d", startup.FailureThreshold)
+	}
+
+	// 覆盖单个字段保留默认探测方式，覆盖探测方式时替换默认的 httpGet
+	nacos.Spec.ReadinessProbe = &v1.Probe{InitialDelaySeconds: 20}
+	nacos.Spec.LivenessProbe = &v1.Probe{Handler: v1.Handler{TCPSocket: &v1.TCPSocketAction{Port: intstr.FromInt(NACOS_PORT)}}}
+	liveness, readiness, _ = probes(nacos)
+	if readiness.InitialDelaySeconds != 20 || readiness.HTTPGet == nil || readiness.PeriodSeconds != 5 {
+		t.Errorf("Unexpected merged readiness probe %+v", readiness)
+	}
+	if liveness.HTTPGet != nil || liveness.TCPSocket == nil || liveness.PeriodSeconds != 10 {
+		t.Errorf("Unexpected merged liveness probe %+v", liveness)
+	}
+}
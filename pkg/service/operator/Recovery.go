@@ -0,0 +1,359 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/go-logr/logr"
+	appv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	nacosgroupv1alpha1 "nacos.io/nacos-operator/api/v1alpha1"
+	"nacos.io/nacos-operator/pkg/service/k8s"
+	"nacos.io/nacos-operator/pkg/util/contains"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// 灾难恢复阶段，记录在 status.recovery.phase
+const (
+	// 就绪成员数不足半数
+	RecoveryQuorumLost = "QuorumLost"
+	// 失去多数派超过 quorumLossSeconds，等待注解确认
+	RecoveryAwaitingConfirmation = "AwaitingConfirmation"
+	// 以存活成员重建单节点集群
+	RecoveryBootstrapping = "Bootstrapping"
+	// 逐个扩容到 spec.replicas
+	RecoveryGrowing = "Growing"
+)
+
+// 确认灾难恢复的注解，值为 status.recovery.candidates 中的一个成员，开始恢复后由 operator 移除
+const RecoveryConfirmAnnotation = "nacos.io/recovery-confirm"
+
+// 恢复过程中清理 raft 数据、阻塞未加入成员的 init 容器
+const RecoveryInitContainerName = "nacos-recovery"
+
+// 默认失去多数派多久后允许恢复
+const DefaultQuorumLossDuration = time.Minute * 10
+
+// 扩容一个成员后重新入队的间隔
+const recoveryRequeueInterval = time.Second * 10
+
+type RecoveryClient struct {
+	k8sService k8s.Services
+	kindClient *KindClient
+	client     client.Client
+	logger     log.Logger
+	recorder   record.EventRecorder
+}
+
+func NewRecoveryClient(logger log.Logger, k8sService k8s.Services, kindClient *KindClient, client client.Client, recorder record.EventRecorder) *RecoveryClient {
+	return &RecoveryClient{
+		k8sService: k8sService,
+		kindClient: kindClient,
+		client:     client,
+		logger:     logger,
+		recorder:   recorder,
+	}
+}
+
+// recovering 正在重建或扩容集群
+func recovering(nacos *nacosgroupv1alpha1.Nacos) bool {
+	phase := nacos.Status.Recovery.Phase
+	return phase == RecoveryBootstrapping || phase == RecoveryGrowing
+}
+
+// clusterReplicas 集群当前应有的成员数，恢复过程中为 status.recovery.replicas，
+// 缩容时离开的成员退出集群前（包括等待升级完成）为 status.scale.from
+func clusterReplicas(nacos *nacosgroupv1alpha1.Nacos) int32 {
+	if recovering(nacos) {
+		return nacos.Status.Recovery.Replicas
+	}
+	if phase := nacos.Status.Scale.Phase; phase == ScalePending || phase == ScaleDraining {
+		return nacos.Status.Scale.From
+	}
+	return *nacos.Spec.Replicas
+}
+
+// statefulSetReplicas StatefulSet 的副本数，恢复过程中保留所有序号的 Pod，未加入的成员阻塞在 init 容器中
+func statefulSetReplicas(nacos *nacosgroupv1alpha1.Nacos) int32 {
+	if recovering(nacos) {
+		return *nacos.Spec.Replicas
+	}
+	return clusterReplicas(nacos)
+}
+
+// clusterOrdinals 集群成员的序号，恢复过程中为存活成员与已加入的成员
+func clusterOrdinals(nacos *nacosgroupv1alpha1.Nacos) []int32 {
+	if recovering(nacos) {
+		return recoveryOrdinals(nacos)
+	}
+	ordinals := make([]int32, 0, clusterReplicas(nacos))
+	for i := int32(0); i < clusterReplicas(nacos); i++ {
+		ordinals = append(ordinals, i)
+	}
+	return ordinals
+}
+
+// recoveryOrdinals 按序号排列的恢复成员：存活成员加上按序号依次加入的 status.recovery.replicas-1 个成员
+func recoveryOrdinals(nacos *nacosgroupv1alpha1.Nacos) []int32 {
+	recovery := nacos.Status.Recovery
+	survivor := podOrdinal(nacos, recovery.Survivor)
+	ordinals := []int32{survivor}
+	for i := int32(0); i < *nacos.Spec.Replicas && int32(len(ordinals)) < recovery.Replicas; i++ {
+		if i != survivor {
+			ordinals = append(ordinals, i)
+		}
+	}
+	sort.Slice(ordinals, func(i, j int) bool { return ordinals[i] < ordinals[j] })
+	return ordinals
+}
+
+func quorumLossDuration(nacos *nacosgroupv1alpha1.Nacos) time.Duration {
+	if nacos.Spec.Recovery.QuorumLossSeconds > 0 {
+		return time.Duration(nacos.Spec.Recovery.QuorumLossSeconds) * time.Second
+	}
+	return DefaultQuorumLossDuration
+}
+
+// MarkQuorumLost 记录失去多数派的时间，开启恢复且持续时间足够时从运行中的成员选出候选成员，等待确认
+func (c *RecoveryClient) MarkQuorumLost(nacos *nacosgroupv1alpha1.Nacos, now time.Time) error {
+	if nacos.Spec.Type != TYPE_CLUSTER || recovering(nacos) {
+		return nil
+	}
+	recovery := &nacos.Status.Recovery
+	if recovery.Phase == "" {
+		recovery.Phase = RecoveryQuorumLost
+		recovery.QuorumLostSince = metav1.Time{Time: now}
+	}
+	if !nacos.Spec.Recovery.Enabled {
+		return nil
+	}
+	if recovery.Phase == RecoveryQuorumLost && now.Sub(recovery.QuorumLostSince.Time) < quorumLossDuration(nacos) {
+		return nil
+	}
+	pods, err := c.k8sService.GetStatefulSetPods(nacos.Namespace, nacos.Name)
+	if err != nil {
+		return err
+	}
+	// 等待确认期间成员可能停止或恢复，每次检查都重新选择
+	survivor := recovery.Survivor
+	recovery.Candidates = survivorCandidates(nacos, pods.Items)
+	recovery.Survivor = ""
+	if len(recovery.Candidates) > 0 {
+		recovery.Survivor = recovery.Candidates[0]
+	}
+	if recovery.Phase == RecoveryAwaitingConfirmation && recovery.Survivor == survivor {
+		return nil
+	}
+	recovery.Phase = RecoveryAwaitingConfirmation
+	if recovery.Survivor == "" {
+		c.recorder.Eventf(nacos, v1.EventTypeWarning, ReasonRecoveryAwaitingConfirmation,
+			"quorum lost since %s, no member is running to rebuild the cluster from",
+			recovery.QuorumLostSince.UTC().Format(time.RFC3339))
+		return nil
+	}
+	c.recorder.Eventf(nacos, v1.EventTypeWarning, ReasonRecoveryAwaitingConfirmation,
+		"quorum lost since %s, annotate %s=%s to rebuild the cluster from %s (candidates: %s), raft data of other members will be lost",
+		recovery.QuorumLostSince.UTC().Format(time.RFC3339), RecoveryConfirmAnnotation, recovery.Survivor, recovery.Survivor,
+		strings.Join(recovery.Candidates, ", "))
+	return nil
+}
+
+// survivorCandidates 运行中的成员按就绪、raft term 从高到低、序号从小到大排序
+func survivorCandidates(nacos *nacosgroupv1alpha1.Nacos, pods []v1.Pod) []string {
+	type candidate struct {
+		name    string
+		ready   bool
+		term    int64
+		ordinal int32
+	}
+	var candidates []candidate
+	for _, pod := range pods {
+		ordinal := podOrdinal(nacos, pod.Name)
+		if ordinal < 0 || ordinal >= *nacos.Spec.Replicas || !nacosRunning(nacos, pod) {
+			continue
+		}
+		c := candidate{name: pod.Name, ready: podReady(pod), ordinal: ordinal}
+		if member := memberOfPod(nacos.Status.Members, pod); member != nil {
+			for _, group := range member.RaftGroups {
+				if group.Term > c.term {
+					c.term = group.Term
+				}
+			}
+		}
+		candidates = append(candidates, c)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].ready != candidates[j].ready {
+			return candidates[i].ready
+		}
+		if candidates[i].term != candidates[j].term {
+			return candidates[i].term > candidates[j].term
+		}
+		return candidates[i].ordinal < candidates[j].ordinal
+	})
+	names := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		names = append(names, c.name)
+	}
+	return names
+}
+
+// nacosRunning Pod 未删除且 Nacos 容器正在运行
+func nacosRunning(nacos *nacosgroupv1alpha1.Nacos, pod v1.Pod) bool {
+	if pod.DeletionTimestamp != nil || pod.Status.Phase != v1.PodRunning {
+		return false
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == nacos.Name {
+			return status.State.Running != nil
+		}
+	}
+	return false
+}
+
+// Recover 确认候选成员后以它重建单节点集群；重建过程中重启仍使用旧模板的 Pod，
+// 由 init 容器在 Nacos 启动前清理 raft 数据
+func (c *RecoveryClient) Recover(nacos *nacosgroupv1alpha1.Nacos) error {
+	recovery := &nacos.Status.Recovery
+	if !nacos.Spec.Recovery.Enabled {
+		if recovering(nacos) {
+			c.logger.Info("recovery disabled, abort", "nacos", nacos.Name, "phase", recovery.Phase)
+			*recovery = nacosgroupv1alpha1.RecoveryStatus{}
+		}
+		return nil
+	}
+	switch recovery.Phase {
+	case RecoveryAwaitingConfirmation:
+		survivor, ok := nacos.Annotations[RecoveryConfirmAnnotation]
+		if !ok || !contains.String(recovery.Candidates, survivor) {
+			return nil
+		}
+		// 确认后成员停止时等待重新选择候选成员
+		pod, err := c.k8sService.GetPod(nacos.Namespace, survivor)
+		if err != nil && !k8sErrors.IsNotFound(err) {
+			return err
+		}
+		if err != nil || !nacosRunning(nacos, *pod) {
+			c.logger.Info("confirmed survivor is not running", "nacos", nacos.Name, "survivor", survivor)
+			return nil
+		}
+		if err := c.removeConfirmation(nacos); err != nil {
+			return err
+		}
+		recovery.Phase = RecoveryBootstrapping
+		recovery.Survivor = survivor
+		recovery.Candidates = nil
+		recovery.Replicas = 1
+		c.recorder.Eventf(nacos, v1.EventTypeWarning, ReasonRecoveryStarted, "rebuild the cluster from %s", recovery.Survivor)
+	case RecoveryBootstrapping, RecoveryGrowing:
+		return c.restartStalePods(nacos)
+	}
+	return nil
+}
+
+// restartStalePods 失去多数派的 Pod 无法就绪，StatefulSet 不会滚动更新它们，需要直接删除，
+// 使存活成员在 init 容器中清理 raft 数据后以新的成员列表启动，未加入的成员停止运行
+func (c *RecoveryClient) restartStalePods(nacos *nacosgroupv1alpha1.Nacos) error {
+	ss, err := c.k8sService.GetStatefulSet(nacos.Namespace, nacos.Name)
+	if err != nil {
+		return err
+	}
+	// StatefulSet controller 处理新模板前 updateRevision 仍是旧版本
+	if ss.Status.UpdateRevision == "" || ss.Status.ObservedGeneration < ss.Generation {
+		return nil
+	}
+	pods, err := c.k8sService.GetStatefulSetPods(nacos.Namespace, nacos.Name)
+	if err != nil {
+		return err
+	}
+	for _, pod := range pods.Items {
+		if pod.DeletionTimestamp != nil || pod.Labels[appv1.ControllerRevisionHashLabelKey] == ss.Status.UpdateRevision {
+			continue
+		}
+		if err := c.k8sService.DeletePod(nacos.Namespace, pod.Name); err != nil && !k8sErrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// buildRecoveryInitContainer 恢复过程中 Nacos 启动前执行：未加入的成员一直等待，
+// 每次恢复中存活成员只清理一次 raft 数据，新加入的成员清空数据目录
+func buildRecoveryInitContainer(nacos *nacosgroupv1alpha1.Nacos, ss *appv1.StatefulSet) v1.Container {
+	members := []string{}
+	for _, ordinal := range recoveryOrdinals(nacos) {
+		members = append(members, fmt.Sprintf("%s-%d", ss.Name, ordinal))
+	}
+	container := v1.Container{
+		Name:  RecoveryInitContainerName,
+		Image: nacos.Spec.Image,
+		Env: []v1.EnvVar{
+			{Name: "RECOVERY_ID", Value: fmt.Sprintf("%d", nacos.Status.Recovery.QuorumLostSince.Unix())},
+			{Name: "RECOVERY_SURVIVOR", Value: nacos.Status.Recovery.Survivor},
+			{Name: "RECOVERY_MEMBERS", Value: strings.Join(members, " ")},
+		},
+		Command: []string{"/bin/sh", "-c", fmt.Sprintf(`case " $RECOVERY_MEMBERS " in
+*" $(hostname) "*) ;;
+*) echo "$(hostname) waits to join the recovering cluster"; while true; do sleep 3600; done ;;
+esac
+marker=%[1]s/.recovery-$RECOVERY_ID
+if [ ! -f "$marker" ]; then
+  if [ "$(hostname)" = "$RECOVERY_SURVIVOR" ]; then
+    rm -rf %[2]s
+  else
+    find %[1]s -mindepth 1 -maxdepth 1 -exec rm -rf {} +
+  fi
+  touch "$marker"
+fi`, NACOS_DATA_DIR, RAFT_PROTOCOL_DIR)},
+	}
+	for _, mount := range ss.Spec.Template.Spec.Containers[0].VolumeMounts {
+		if mount.MountPath == NACOS_DATA_DIR {
+			container.VolumeMounts = append(container.VolumeMounts, mount)
+		}
+	}
+	return container
+}
+
+// MarkHealthy 集群检查通过：自行恢复多数派时清除记录；重建过程中逐个扩容，返回 true 表示扩容了一个成员
+func (c *RecoveryClient) MarkHealthy(nacos *nacosgroupv1alpha1.Nacos) (bool, error) {
+	recovery := &nacos.Status.Recovery
+	switch recovery.Phase {
+	case "":
+		return false, nil
+	case RecoveryQuorumLost, RecoveryAwaitingConfirmation:
+		c.logger.Info("quorum restored", "nacos", nacos.Name)
+		*recovery = nacosgroupv1alpha1.RecoveryStatus{}
+		return false, nil
+	case RecoveryBootstrapping:
+		recovery.Phase = RecoveryGrowing
+	}
+
+	if recovery.Replicas >= *nacos.Spec.Replicas {
+		c.recorder.Eventf(nacos, v1.EventTypeNormal, ReasonRecoveryCompleted, "cluster rebuilt from %s with %d members", recovery.Survivor, recovery.Replicas)
+		*recovery = nacosgroupv1alpha1.RecoveryStatus{}
+		return false, nil
+	}
+	// 新成员由 init 容器清空数据目录，避免旧的 raft 配置阻止其加入
+	recovery.Replicas++
+	c.recorder.Eventf(nacos, v1.EventTypeNormal, ReasonRecoveryGrowing, "grow the cluster to %d/%d members", recovery.Replicas, *nacos.Spec.Replicas)
+	return true, nil
+}
+
+// removeConfirmation 移除确认注解，避免下次失去多数派时直接开始恢复
+func (c *RecoveryClient) removeConfirmation(nacos *nacosgroupv1alpha1.Nacos) error {
+	obj := nacos.DeepCopy()
+	patch := client.MergeFrom(obj.DeepCopy())
+	delete(obj.Annotations, RecoveryConfirmAnnotation)
+	if err := c.client.Patch(context.TODO(), obj, patch); err != nil {
+		return err
+	}
+	delete(nacos.Annotations, RecoveryConfirmAnnotation)
+	nacos.ResourceVersion = obj.ResourceVersion
+	return nil
+}
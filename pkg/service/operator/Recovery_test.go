@@ -0,0 +1,191 @@
+package operator
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	nacosgroupv1alpha1 "nacos.io/nacos-operator/api/v1alpha1"
+)
+
+func TestMarkQuorumLost(t *testing.T) {
+	nacos := newTestNacos("nacos")
+	// nacos-0 已停止，nacos-2 的 raft term 最高
+	kindClient, fakeClient := newTestKindClient(nacos, newTestStatefulSet(nacos),
+		newTestPod("nacos-0", false, false),
+		newTestPod("nacos-1", true, true),
+		newTestPod("nacos-2", true, true),
+	)
+	client := NewRecoveryClient(logr.Discard(), kindClient.k8sService, kindClient, kindClient.client, kindClient.recorder)
+	nacos.Status.Members = []nacosgroupv1alpha1.NacosMember{
+		newPartitionMember("10.0.0.2:8848", "nacos-1", "10.0.0.1:7848", 3),
+		newPartitionMember("10.0.0.3:8848", "nacos-2", "10.0.0.1:7848", 5),
+	}
+	now := time.Now()
+
+	// 未开启恢复时只记录
+	if err := client.MarkQuorumLost(nacos, now); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if nacos.Status.Recovery.Phase != RecoveryQuorumLost || !nacos.Status.Recovery.QuorumLostSince.Time.Equal(now) {
+		t.Fatalf("Expected quorum loss to be recorded, got %+v", nacos.Status.Recovery)
+	}
+	_ = client.MarkQuorumLost(nacos, now.Add(time.Hour))
+	if nacos.Status.Recovery.Phase != RecoveryQuorumLost {
+		t.Errorf("Expected no confirmation request when recovery is disabled, got %s", nacos.Status.Recovery.Phase)
+	}
+
+	nacos.Spec.Recovery.Enabled = true
+	nacos.Spec.Recovery.QuorumLossSeconds = 60
+	_ = client.MarkQuorumLost(nacos, now.Add(time.Second*30))
+	if nacos.Status.Recovery.Phase != RecoveryQuorumLost {
+		t.Errorf("Expected to wait for quorumLossSeconds, got %s", nacos.Status.Recovery.Phase)
+	}
+	_ = client.MarkQuorumLost(nacos, now.Add(time.Minute))
+	recovery := nacos.Status.Recovery
+	if recovery.Phase != RecoveryAwaitingConfirmation || recovery.Survivor != "nacos-2" || strings.Join(recovery.Candidates, ",") != "nacos-2,nacos-1" {
+		t.Fatalf("Expected to await confirmation for nacos-2, got %+v", recovery)
+	}
+
+	// 就绪的成员优先
+	_ = fakeClient.Tracker().Update(v1.SchemeGroupVersion.WithResource("pods"), newTestPod("nacos-2", true, false), "default")
+	_ = client.MarkQuorumLost(nacos, now.Add(time.Minute*2))
+	if recovery := nacos.Status.Recovery; recovery.Survivor != "nacos-1" || strings.Join(recovery.Candidates, ",") != "nacos-1,nacos-2" {
+		t.Errorf("Expected ready nacos-1 to be preferred, got %+v", recovery)
+	}
+
+	// 没有运行中的成员
+	for _, name := range []string{"nacos-1", "nacos-2"} {
+		_ = fakeClient.Tracker().Update(v1.SchemeGroupVersion.WithResource("pods"), newTestPod(name, false, false), "default")
+	}
+	_ = client.MarkQuorumLost(nacos, now.Add(time.Minute*3))
+	if recovery := nacos.Status.Recovery; recovery.Phase != RecoveryAwaitingConfirmation || recovery.Survivor != "" || len(recovery.Candidates) != 0 {
+		t.Errorf("Expected no survivor without running members, got %+v", recovery)
+	}
+
+	// 多数派自行恢复
+	if grown, _ := client.MarkHealthy(nacos); grown || nacos.Status.Recovery.Phase != "" {
+		t.Errorf("Expected recovery status to be cleared, got %+v", nacos.Status.Recovery)
+	}
+}
+
+func TestRecoverFromConfirmedCandidate(t *testing.T) {
+	nacos := newTestNacos("nacos")
+	nacos.Spec.Recovery.Enabled = true
+	nacos.Status.Recovery = nacosgroupv1alpha1.RecoveryStatus{
+		Phase:      RecoveryAwaitingConfirmation,
+		Survivor:   "nacos-2",
+		Candidates: []string{"nacos-2", "nacos-1"},
+	}
+	kindClient, _ := newTestKindClient(nacos, newTestStatefulSet(nacos), newTestPod("nacos-1", true, false), newTestPod("nacos-2", true, false))
+	client := NewRecoveryClient(logr.Discard(), kindClient.k8sService, kindClient, kindClient.client, kindClient.recorder)
+
+	// 注解不是候选成员时不开始
+	nacos.Annotations = map[string]string{RecoveryConfirmAnnotation: "nacos-0"}
+	if err := client.Recover(nacos); err != nil || nacos.Status.Recovery.Phase != RecoveryAwaitingConfirmation {
+		t.Fatalf("Expected to keep waiting for a candidate, got %+v %v", nacos.Status.Recovery, err)
+	}
+
+	nacos.Annotations[RecoveryConfirmAnnotation] = "nacos-1"
+	if err := client.Recover(nacos); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	recovery := nacos.Status.Recovery
+	if recovery.Phase != RecoveryBootstrapping || recovery.Survivor != "nacos-1" || recovery.Replicas != 1 {
+		t.Fatalf("Expected to rebuild from nacos-1, got %+v", recovery)
+	}
+	if _, ok := nacos.Annotations[RecoveryConfirmAnnotation]; ok {
+		t.Errorf("Expected the confirmation annotation to be removed")
+	}
+}
+
+func TestMarkHealthyGrowsMemberByMember(t *testing.T) {
+	nacos := newTestNacos("nacos")
+	nacos.Spec.Recovery.Enabled = true
+	kindClient, _ := newTestKindClient(nacos, newTestStatefulSet(nacos))
+	client := NewRecoveryClient(logr.Discard(), kindClient.k8sService, kindClient, kindClient.client, kindClient.recorder)
+	nacos.Status.Recovery = nacosgroupv1alpha1.RecoveryStatus{Phase: RecoveryBootstrapping, Survivor: "nacos-1", Replicas: 1}
+	if clusterReplicas(nacos) != 1 || statefulSetReplicas(nacos) != 3 {
+		t.Fatalf("Expected a single member in 3 pods while bootstrapping, got %d %d", clusterReplicas(nacos), statefulSetReplicas(nacos))
+	}
+
+	// 从存活成员开始，按序号依次加入
+	for _, expected := range [][]int32{{0, 1}, {0, 1, 2}} {
+		grown, err := client.MarkHealthy(nacos)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		ordinals := clusterOrdinals(nacos)
+		if !grown || nacos.Status.Recovery.Phase != RecoveryGrowing || len(ordinals) != len(expected) {
+			t.Fatalf("Expected to grow to %v, got %v %+v", expected, ordinals, nacos.Status.Recovery)
+		}
+		for i := range expected {
+			if ordinals[i] != expected[i] {
+				t.Fatalf("Expected members %v, got %v", expected, ordinals)
+			}
+		}
+	}
+
+	grown, err := client.MarkHealthy(nacos)
+	if err != nil || grown || nacos.Status.Recovery.Phase != "" || clusterReplicas(nacos) != 3 {
+		t.Errorf("Expected recovery to complete, got %+v", nacos.Status.Recovery)
+	}
+}
+
+func TestBuildStatefulsetClusterWhileRecovering(t *testing.T) {
+	nacos := newTestNacos("nacos")
+	nacos.Spec.Volume = nacosgroupv1alpha1.Storage{VolumeClaimTemplate: &v1.PersistentVolumeClaim{
+		Spec: v1.PersistentVolumeClaimSpec{Resources: v1.ResourceRequirements{
+			Requests: v1.ResourceList{v1.ResourceStorage: resource.MustParse("1Gi")},
+		}},
+	}}
+	nacos.Status.Recovery = nacosgroupv1alpha1.RecoveryStatus{Phase: RecoveryBootstrapping, Survivor: "nacos-2", Replicas: 1}
+	kindClient, _ := newTestKindClient(nil)
+	ss, err := kindClient.buildStatefulset(nacos)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	ss = kindClient.buildStatefulsetCluster(nacos, ss)
+
+	// 保留所有序号的 Pod，NACOS_SERVERS 只有存活成员
+	if *ss.Spec.Replicas != 3 {
+		t.Errorf("Expected 3 replicas while recovering, got %d", *ss.Spec.Replicas)
+	}
+	for _, env := range ss.Spec.Template.Spec.Containers[0].Env {
+		if env.Name == "NACOS_SERVERS" && (!strings.HasPrefix(env.Value, "nacos-2.") || strings.Contains(env.Value, " ")) {
+			t.Errorf("Expected NACOS_SERVERS to only contain nacos-2, got %q", env.Value)
+		}
+	}
+	var init *v1.Container
+	for i, container := range ss.Spec.Template.Spec.InitContainers {
+		if container.Name == RecoveryInitContainerName {
+			init = &ss.Spec.Template.Spec.InitContainers[i]
+		}
+	}
+	if init == nil {
+		t.Fatalf("Expected the recovery init container")
+	}
+	env := map[string]string{}
+	for _, e := range init.Env {
+		env[e.Name] = e.Value
+	}
+	if env["RECOVERY_MEMBERS"] != "nacos-2" || env["RECOVERY_SURVIVOR"] != "nacos-2" {
+		t.Errorf("Unexpected recovery env %v", env)
+	}
+	if len(init.VolumeMounts) != 1 || init.VolumeMounts[0].MountPath != NACOS_DATA_DIR {
+		t.Errorf("Expected the data volume to be mounted, got %v", init.VolumeMounts)
+	}
+
+	// 恢复完成后不再渲染 init 容器
+	nacos.Status.Recovery = nacosgroupv1alpha1.RecoveryStatus{}
+	ss, _ = kindClient.buildStatefulset(nacos)
+	ss = kindClient.buildStatefulsetCluster(nacos, ss)
+	for _, container := range ss.Spec.Template.Spec.InitContainers {
+		if container.Name == RecoveryInitContainerName {
+			t.Errorf("Expected no recovery init container after recovery")
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package operator
+
+import (
+	"errors"
+	"time"
+
+	nacosgroupv1alpha1 "nacos.io/nacos-operator/api/v1alpha1"
+	myErrors "nacos.io/nacos-operator/pkg/errors"
+)
+
+// 调谐流水线中的步骤名称，同时作为 StepPolicy 的 key
+const (
+	StepPreCheck         = "PreCheck"
+	StepPGEnsure         = "PGEnsure"
+	StepRotateAdmin      = "RotateAdmin"
+	StepRecover          = "Recover"
+	StepUpgrade          = "Upgrade"
+	StepScaleDown        = "ScaleDown"
+	StepMakeEnsure       = "MakeEnsure"
+	StepCheckAndMakeHeal = "CheckAndMakeHeal"
+	StepUpdateStatus     = "UpdateStatus"
+)
+
+// 默认的创建宽限期：Creating 阶段在此时间内的失败不会把 CR 标记为 Failed
+const DefaultCreatingGracePeriod = time.Minute * 3
+
+// 初始化 Phase 后重新入队的间隔
+const initRequeueInterval = time.Second * 5
+
+type StepAction int
+
+const (
+	// 继续执行下一个步骤
+	ActionContinue StepAction = iota
+	// 中止本轮调谐，按 RequeueAfter 重新入队（不是错误）
+	ActionRequeue
+	// 步骤失败，交给 controller-runtime 的限速队列退避重试
+	ActionError
+	// 重试无法修复的错误（例如 CR 参数错误），等待 CR 变更后再调谐
+	ActionTerminal
+)
+
+// StepResult 调谐步骤的执行结果
+type StepResult struct {
+	Action StepAction
+	// ActionRequeue 时的重新入队间隔，为 0 时立即重新入队
+	RequeueAfter time.Duration
+	// ActionError / ActionTerminal 时的错误
+	Err *myErrors.Err
+}
+
+func Continue() StepResult {
+	return StepResult{Action: ActionContinue}
+}
+
+func Requeue(after time.Duration) StepResult {
+	return StepResult{Action: ActionRequeue, RequeueAfter: after}
+}
+
+// Fail 返回可重试的失败结果
+func Fail(err error) StepResult {
+	return StepResult{Action: ActionError, Err: toErr(err)}
+}
+
+// Terminal 返回不可重试的失败结果
+func Terminal(err error) StepResult {
+	return StepResult{Action: ActionTerminal, Err: toErr(err)}
+}
+
+// ResultFromError 根据错误码选择结果：参数错误不重试，其余错误退避重试，nil 继续执行
+func ResultFromError(err error) StepResult {
+	if err == nil {
+		return Continue()
+	}
+	myErr := toErr(err)
+	if myErr.Code == myErrors.CODE_PARAMETER_ERROR {
+		return StepResult{Action: ActionTerminal, Err: myErr}
+	}
+	return StepResult{Action: ActionError, Err: myErr}
+}
+
+func toErr(err error) *myErrors.Err {
+	var myErr *myErrors.Err
+	if errors.As(err, &myErr) {
+		return myErr
+	}
+	return myErrors.NewErr(err)
+}
+
+// StepPolicy 步骤失败时的处理策略
+type StepPolicy struct {
+	// CR 处于 Creating 且创建时间未超过该值时，失败只重试，不标记为 Failed
+	CreatingGracePeriod time.Duration
+	// 大于 0 时失败后按固定间隔重新入队，否则使用 controller-runtime 的限速退避
+	RequeueAfter time.Duration
+}
+
+// InGracePeriod 判断 CR 是否仍处于创建宽限期内
+func (p StepPolicy) InGracePeriod(nacos *nacosgroupv1alpha1.Nacos) bool {
+	return nacos.Status.Phase == nacosgroupv1alpha1.PhaseCreating &&
+		nacos.CreationTimestamp.Add(p.CreatingGracePeriod).After(time.Now())
+}
+
+// DefaultStepPolicies 返回每个步骤的默认策略
+func DefaultStepPolicies(creatingGracePeriod time.Duration) map[string]StepPolicy {
+	policies := map[string]StepPolicy{}
+	for _, step := range []string{
+		StepPreCheck,
+		StepPGEnsure,
+		StepRotateAdmin,
+		StepRecover,
+		StepUpgrade,
+		StepScaleDown,
+		StepMakeEnsure,
+		StepCheckAndMakeHeal,
+		StepUpdateStatus,
+	} {
+		policies[step] = StepPolicy{CreatingGracePeriod: creatingGracePeriod}
+	}
+	return policies
+}
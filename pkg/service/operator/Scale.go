@@ -0,0 +1,222 @@
+package operator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	nacosgroupv1alpha1 "nacos.io/nacos-operator/api/v1alpha1"
+	myErrors "nacos.io/nacos-operator/pkg/errors"
+	"nacos.io/nacos-operator/pkg/service/k8s"
+	nacosClient "nacos.io/nacos-operator/pkg/service/nacos"
+)
+
+// 缩容阶段，记录在 status.scale.phase
+const (
+	// 分批升级中减少了 spec.replicas，升级完成前保持 StatefulSet 的副本数
+	ScalePending = "Pending"
+	// 离开的成员仍在运行，等待其余成员的节点列表中不再有它们
+	ScaleDraining = "Draining"
+	// StatefulSet 已减少副本数，等待离开的 Pod 删除
+	ScaleRemoving = "Removing"
+)
+
+// 其余成员超过该时间仍未移除离开的成员时直接减少副本数
+const scaleDrainTimeout = time.Minute * 5
+
+// 缩容过程中重新入队的间隔
+const scaleRequeueInterval = time.Second * 5
+
+type ScaleClient struct {
+	k8sService  k8s.Services
+	kindClient  *KindClient
+	checkClient *CheckClient
+	logger      log.Logger
+	recorder    record.EventRecorder
+}
+
+func NewScaleClient(logger log.Logger, k8sService k8s.Services, kindClient *KindClient, checkClient *CheckClient, recorder record.EventRecorder) *ScaleClient {
+	return &ScaleClient{
+		k8sService:  k8sService,
+		kindClient:  kindClient,
+		checkClient: checkClient,
+		logger:      logger,
+		recorder:    recorder,
+	}
+}
+
+// scalingDown 缩容尚未完成
+func scalingDown(nacos *nacosgroupv1alpha1.Nacos) bool {
+	return nacos.Status.Scale.Phase != ""
+}
+
+// ScaleDown 集群缩容时先让离开的成员退出集群，再减少 StatefulSet 的副本数。
+// 返回 true 表示缩容未完成，需要保存状态后重新入队，不执行后续步骤
+func (c *ScaleClient) ScaleDown(nacos *nacosgroupv1alpha1.Nacos, now time.Time) (bool, error) {
+	scale := &nacos.Status.Scale
+	if nacos.Spec.Type != TYPE_CLUSTER || recovering(nacos) {
+		if scalingDown(nacos) {
+			c.logger.Info("scale down aborted", "nacos", nacos.Name, "phase", scale.Phase)
+			*scale = nacosgroupv1alpha1.ScaleStatus{}
+		}
+		return false, nil
+	}
+	// 缩容过程中修改了 spec.replicas，按新的副本数重新开始
+	if scalingDown(nacos) && scale.To != *nacos.Spec.Replicas {
+		c.logger.Info("replicas changed while scaling down", "nacos", nacos.Name, "to", scale.To, "replicas", *nacos.Spec.Replicas)
+		*scale = nacosgroupv1alpha1.ScaleStatus{}
+	}
+	ss, err := c.k8sService.GetStatefulSet(nacos.Namespace, nacos.Name)
+	if err != nil {
+		if k8sErrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if scale.Phase == "" || scale.Phase == ScalePending {
+		if ss.Spec.Replicas == nil || *ss.Spec.Replicas <= *nacos.Spec.Replicas {
+			*scale = nacosgroupv1alpha1.ScaleStatus{}
+			return false, nil
+		}
+		// 升级完成后再缩容，避免 partition 与副本数同时变化；等待期间不阻塞升级
+		if upgrading(nacos) {
+			if scale.Phase == "" {
+				*scale = nacosgroupv1alpha1.ScaleStatus{Phase: ScalePending, From: *ss.Spec.Replicas, To: *nacos.Spec.Replicas}
+				c.recorder.Eventf(nacos, v1.EventTypeNormal, ReasonScaleDownDeferred, "scale down from %d to %d members after the upgrade completes", scale.From, scale.To)
+			}
+			return false, nil
+		}
+		c.start(nacos, *ss.Spec.Replicas, now)
+	}
+
+	if scale.Phase == ScaleDraining {
+		drained, err := c.drain(nacos, now)
+		if err != nil || !drained {
+			return !drained, err
+		}
+		scale.Phase = ScaleRemoving
+	}
+
+	// 按目标副本数重新渲染 StatefulSet，StatefulSet 从最大序号开始删除 Pod
+	if err := c.kindClient.EnsureStatefulsetCluster(nacos); err != nil {
+		return false, err
+	}
+	pods, err := c.k8sService.GetStatefulSetPods(nacos.Namespace, nacos.Name)
+	if err != nil {
+		return false, err
+	}
+	for _, pod := range pods.Items {
+		if podOrdinal(nacos, pod.Name) >= scale.To {
+			return true, nil
+		}
+	}
+	c.recorder.Eventf(nacos, v1.EventTypeNormal, ReasonScaleDownCompleted, "scaled down from %d to %d members", scale.From, scale.To)
+	*scale = nacosgroupv1alpha1.ScaleStatus{}
+	return false, nil
+}
+
+// start 记录离开的成员，status.members 中没有的成员（如已经停止）不需要退出集群
+func (c *ScaleClient) start(nacos *nacosgroupv1alpha1.Nacos, from int32, now time.Time) {
+	to := *nacos.Spec.Replicas
+	var addresses []string
+	for _, member := range nacos.Status.Members {
+		if ordinal := podOrdinal(nacos, member.PodName); ordinal >= to && ordinal < from {
+			addresses = append(addresses, member.Address)
+		}
+	}
+	nacos.Status.Scale = nacosgroupv1alpha1.ScaleStatus{
+		Phase:     ScaleDraining,
+		From:      from,
+		To:        to,
+		Members:   addresses,
+		StartTime: metav1.Time{Time: now},
+	}
+	c.recorder.Eventf(nacos, v1.EventTypeNormal, ReasonScaleDownStarted, "scale down from %d to %d members, members leaving: %s",
+		from, to, strings.Join(addresses, ", "))
+}
+
+// drain 通过其余成员的 member-leave 接口移除离开的成员，返回 true 表示其余成员的节点列表中已没有这些成员
+func (c *ScaleClient) drain(nacos *nacosgroupv1alpha1.Nacos, now time.Time) (bool, error) {
+	scale := &nacos.Status.Scale
+	if len(scale.Members) == 0 {
+		return true, nil
+	}
+	pods, err := c.k8sService.GetStatefulSetReadPod(nacos.Namespace, nacos.Name)
+	if err != nil {
+		return false, err
+	}
+	var ips []string
+	for _, pod := range pods {
+		if ordinal := podOrdinal(nacos, pod.Name); ordinal >= 0 && ordinal < scale.To {
+			ips = append(ips, pod.Status.PodIP)
+		}
+	}
+	if len(ips) == 0 {
+		return false, myErrors.New(myErrors.CODE_CLUSTER_FAILE, "no ready member is left to remove %s", strings.Join(scale.Members, ", "))
+	}
+	endpoint, credentials, err := c.checkClient.access(nacos)
+	if err != nil {
+		return false, err
+	}
+
+	remaining := map[string]bool{}
+	for _, result := range c.checkClient.nacosClient.ProbeClusterNodes(endpoint, ips, nacosProbeWorkers, credentials) {
+		if result.Err != nil {
+			remaining[result.IP] = true
+			continue
+		}
+		for _, server := range result.Servers.Data {
+			for _, address := range scale.Members {
+				if server.Address == address {
+					remaining[result.IP] = true
+				}
+			}
+		}
+	}
+	if len(remaining) == 0 {
+		c.recorder.Eventf(nacos, v1.EventTypeNormal, ReasonMembersLeft, "%s left the cluster", strings.Join(scale.Members, ", "))
+		return true, nil
+	}
+	if now.Sub(scale.StartTime.Time) >= scaleDrainTimeout {
+		c.recorder.Eventf(nacos, v1.EventTypeWarning, ReasonMembersLeaveSkipped, "%d members still list %s after %s, remove them anyway",
+			len(remaining), strings.Join(scale.Members, ", "), scaleDrainTimeout)
+		return true, nil
+	}
+
+	// 由一个成员处理离开请求并通知其余成员，重复请求没有副作用
+	err = c.checkClient.nacosClient.LeaveMembers(endpoint, ips[0], credentials, scale.Members)
+	if nacosClient.IsAPINotFound(err) {
+		c.recorder.Eventf(nacos, v1.EventTypeWarning, ReasonMembersLeaveSkipped, "member leave api is not available, remove %s without leaving", strings.Join(scale.Members, ", "))
+		return true, nil
+	}
+	return false, err
+}
+
+// podOrdinal 返回 StatefulSet Pod 名称中的序号，不是该 StatefulSet 的 Pod 时返回 -1
+func podOrdinal(nacos *nacosgroupv1alpha1.Nacos, podName string) int32 {
+	suffix := strings.TrimPrefix(podName, nacos.Name+"-")
+	if suffix == podName {
+		return -1
+	}
+	ordinal, err := strconv.Atoi(suffix)
+	if err != nil {
+		return -1
+	}
+	return int32(ordinal)
+}
+
+// scaleMessage 描述缩容进度
+func scaleMessage(nacos *nacosgroupv1alpha1.Nacos) string {
+	scale := nacos.Status.Scale
+	if scale.Phase == ScaleDraining {
+		return fmt.Sprintf("removing %d members from the cluster before scaling down from %d to %d", len(scale.Members), scale.From, scale.To)
+	}
+	return fmt.Sprintf("waiting for pods to be deleted while scaling down from %d to %d", scale.From, scale.To)
+}
@@ -0,0 +1,180 @@
+package operator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+	nacosgroupv1alpha1 "nacos.io/nacos-operator/api/v1alpha1"
+	"nacos.io/nacos-operator/pkg/service/k8s"
+)
+
+// newMemberServer 模拟集群节点接口与 member-leave 接口，离开的成员从节点列表中移除
+func newMemberServer(addresses []string) (*httptest.Server, func() [][]string) {
+	var mu sync.Mutex
+	var leaves [][]string
+	members := map[string]bool{}
+	for _, address := range addresses {
+		members[address] = true
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case "/nacos/v2/core/cluster/node/list":
+			data := []string{}
+			for _, address := range addresses {
+				if members[address] {
+					data = append(data, fmt.Sprintf(`{"address":%q,"state":"UP"}`, address))
+				}
+			}
+			fmt.Fprintf(w, `{"code":0,"data":[%s]}`, strings.Join(data, ","))
+		case "/nacos/v1/core/cluster/server/leave":
+			var left []string
+			_ = json.NewDecoder(r.Body).Decode(&left)
+			for _, address := range left {
+				members[address] = false
+			}
+			leaves = append(leaves, left)
+			w.Write([]byte(`{"code":200,"message":"ok"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return server, func() [][]string {
+		mu.Lock()
+		defer mu.Unlock()
+		return leaves
+	}
+}
+
+func TestScaleDownRemovesMembersFirst(t *testing.T) {
+	address := func(i int) string {
+		return fmt.Sprintf("nacos-%d.nacos-headless.default.svc.cluster.local:8848", i)
+	}
+	server, leaves := newMemberServer([]string{address(0), address(1), address(2)})
+	defer server.Close()
+	port := server.URL[strings.LastIndex(server.URL, ":")+1:]
+
+	scheme := runtime.NewScheme()
+	_ = nacosgroupv1alpha1.AddToScheme(scheme)
+	fakeClient := fake.NewSimpleClientset()
+	recorder := record.NewFakeRecorder(20)
+	service := k8s.NewK8sService(fakeClient, nil, logr.Discard())
+	kindClient := &KindClient{k8sService: service, logger: logr.Discard(), scheme: scheme, recorder: recorder}
+	client := NewScaleClient(logr.Discard(), service, kindClient, NewCheckClient(logr.Discard(), service, nil), recorder)
+
+	replicas := int32(3)
+	nacos := &nacosgroupv1alpha1.Nacos{
+		ObjectMeta: metav1.ObjectMeta{Name: "nacos", Namespace: "default", UID: "test-uid"},
+		Spec: nacosgroupv1alpha1.NacosSpec{
+			Type:     TYPE_CLUSTER,
+			Image:    "nacos/nacos-server:v2.3.2",
+			Replicas: &replicas,
+			Env:      []v1.EnvVar{{Name: "NACOS_APPLICATION_PORT", Value: port}},
+		},
+	}
+	if err := kindClient.EnsureStatefulsetCluster(nacos); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	ss, _ := service.GetStatefulSet("default", "nacos")
+	for i := 0; i < 3; i++ {
+		pod := &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "nacos-" + strconv.Itoa(i), Namespace: "default", Labels: ss.Spec.Selector.MatchLabels},
+			Status: v1.PodStatus{
+				Phase:      v1.PodRunning,
+				PodIP:      "127.0.0.1",
+				Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionTrue}},
+			},
+		}
+		if _, err := fakeClient.CoreV1().Pods("default").Create(context.TODO(), pod, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		nacos.Status.Members = append(nacos.Status.Members, nacosgroupv1alpha1.NacosMember{Address: address(i), PodName: pod.Name})
+	}
+	statefulSetReplicas := func() int32 {
+		ss, _ := service.GetStatefulSet("default", "nacos")
+		return *ss.Spec.Replicas
+	}
+
+	// 分批升级中减少副本数，升级完成前保持 StatefulSet 的副本数，不让成员离开
+	replicas = 1
+	now := time.Now()
+	nacos.Status.Upgrade = nacosgroupv1alpha1.UpgradeStatus{Phase: UpgradeUpgrading, Partition: 2, PodName: "nacos-2"}
+	pending, err := client.ScaleDown(nacos, now)
+	if err != nil || pending || nacos.Status.Scale.Phase != ScalePending || clusterReplicas(nacos) != 3 {
+		t.Fatalf("Expected the scale down to wait for the upgrade, got %v %v %+v", pending, err, nacos.Status.Scale)
+	}
+	if err := kindClient.EnsureStatefulsetCluster(nacos); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if statefulSetReplicas() != 3 || len(leaves()) != 0 {
+		t.Fatalf("Expected 3 replicas and no member leaving during the upgrade, got %d %v", statefulSetReplicas(), leaves())
+	}
+	nacos.Status.Upgrade = nacosgroupv1alpha1.UpgradeStatus{}
+
+	// 离开的成员退出集群前不减少副本数
+	pending, err = client.ScaleDown(nacos, now)
+	if err != nil || !pending {
+		t.Fatalf("Expected to wait for members to leave, got %v %v", pending, err)
+	}
+	scale := nacos.Status.Scale
+	if scale.Phase != ScaleDraining || scale.From != 3 || scale.To != 1 || len(scale.Members) != 2 || clusterReplicas(nacos) != 3 {
+		t.Fatalf("Unexpected scale status %+v", scale)
+	}
+	if len(leaves()) != 1 || strings.Join(leaves()[0], " ") != address(1)+" "+address(2) {
+		t.Errorf("Expected nacos-1 and nacos-2 to leave, got %v", leaves())
+	}
+	if statefulSetReplicas() != 3 {
+		t.Errorf("Expected the statefulset to keep 3 replicas, got %d", statefulSetReplicas())
+	}
+
+	// 其余成员移除离开的成员后减少副本数，等待 Pod 删除
+	pending, err = client.ScaleDown(nacos, now.Add(time.Second*5))
+	if err != nil || !pending || nacos.Status.Scale.Phase != ScaleRemoving {
+		t.Fatalf("Expected to wait for the pods to be deleted, got %v %v %+v", pending, err, nacos.Status.Scale)
+	}
+	if statefulSetReplicas() != 1 || clusterReplicas(nacos) != 1 {
+		t.Errorf("Expected the statefulset to be scaled to 1, got %d", statefulSetReplicas())
+	}
+
+	for _, name := range []string{"nacos-1", "nacos-2"} {
+		_ = fakeClient.CoreV1().Pods("default").Delete(context.TODO(), name, metav1.DeleteOptions{})
+	}
+	pending, err = client.ScaleDown(nacos, now.Add(time.Second*10))
+	if err != nil || pending || scalingDown(nacos) {
+		t.Errorf("Expected the scale down to complete, got %v %v %+v", pending, err, nacos.Status.Scale)
+	}
+	events := []string{}
+	for len(recorder.Events) > 0 {
+		events = append(events, <-recorder.Events)
+	}
+	joined := strings.Join(events, "\n")
+	for _, reason := range []string{ReasonScaleDownDeferred, ReasonScaleDownStarted, ReasonMembersLeft, ReasonScaleDownCompleted} {
+		if !strings.Contains(joined, reason) {
+			t.Errorf("Expected %s event, got %v", reason, events)
+		}
+	}
+}
+
+func TestPodOrdinal(t *testing.T) {
+	nacos := &nacosgroupv1alpha1.Nacos{ObjectMeta: metav1.ObjectMeta{Name: "nacos"}}
+	for name, want := range map[string]int32{"nacos-2": 2, "nacos-10": 10, "nacos-canary-0": -1, "other-1": -1, "": -1} {
+		if got := podOrdinal(nacos, name); got != want {
+			t.Errorf("podOrdinal(%q) = %d, want %d", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package operator
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	nacosgroupv1alpha1 "nacos.io/nacos-operator/api/v1alpha1"
+	myErrors "nacos.io/nacos-operator/pkg/errors"
+)
+
+// Nacos 2.x 集群内部 gRPC 端口
+const CLUSTER_RPC_PORT = 9849
+
+type defaultServicePort struct {
+	name    string
+	port    int32
+	enabled bool
+}
+
+// 端口名称沿用已创建的 Service，修改会导致 nodePort 重新分配
+var (
+	mainServicePorts = []defaultServicePort{
+		{name: "client", port: NACOS_PORT, enabled: true},
+		{name: "rpc", port: RAFT_PORT, enabled: true},
+		{name: "new-rpc", port: NEW_RAFT_PORT, enabled: true},
+		{name: "cluster-rpc", port: CLUSTER_RPC_PORT},
+	}
+	clientServicePorts = []defaultServicePort{
+		{name: "client", port: NACOS_PORT, enabled: true},
+		{name: "rpc", port: NEW_RAFT_PORT, enabled: true},
+		{name: "raft", port: RAFT_PORT},
+		{name: "cluster-rpc", port: CLUSTER_RPC_PORT},
+	}
+)
+
+// enabledServicePorts 默认开启的端口
+func enabledServicePorts(defaults []defaultServicePort) []v1.ServicePort {
+	ports, _ := servicePorts(defaults, nil, "")
+	return ports
+}
+
+// servicePorts 按 name 将覆盖配置应用到默认端口，未匹配的作为新增端口
+func servicePorts(defaults []defaultServicePort, overrides []nacosgroupv1alpha1.ServicePortSpec, field string) ([]v1.ServicePort, error) {
+	byName := map[string]nacosgroupv1alpha1.ServicePortSpec{}
+	for _, override := range overrides {
+		if _, ok := byName[override.Name]; ok || override.Name == "" {
+			return nil, myErrors.New(myErrors.CODE_PARAMETER_ERROR, myErrors.MSG_PARAMETER_ERROT, field+".ports.name", fmt.Sprintf("%q", override.Name))
+		}
+		byName[override.Name] = override
+	}
+
+	ports := []v1.ServicePort{}
+	known := map[string]bool{}
+	for _, d := range defaults {
+		known[d.name] = true
+		port := v1.ServicePort{Name: d.name, Port: d.port, Protocol: v1.ProtocolTCP}
+		override, ok := byName[d.name]
+		if !ok {
+			if d.enabled {
+				ports = append(ports, port)
+			}
+			continue
+		}
+		if override.Enabled != nil && !*override.Enabled || override.Enabled == nil && !d.enabled {
+			continue
+		}
+		if override.Port > 0 && override.Port != d.port {
+			port.Port = override.Port
+			port.TargetPort = intstr.FromInt(int(d.port))
+		}
+		if override.TargetPort > 0 {
+			port.TargetPort = intstr.FromInt(int(override.TargetPort))
+		}
+		port.NodePort = override.NodePort
+		ports = append(ports, port)
+	}
+	for _, override := range overrides {
+		if known[override.Name] || override.Enabled != nil && !*override.Enabled {
+			continue
+		}
+		if override.Port <= 0 {
+			return nil, myErrors.New(myErrors.CODE_PARAMETER_ERROR, myErrors.MSG_PARAMETER_ERROT, field+".ports."+override.Name+".port", override.Port)
+		}
+		port := v1.ServicePort{Name: override.Name, Port: override.Port, Protocol: v1.ProtocolTCP, NodePort: override.NodePort}
+		if override.TargetPort > 0 {
+			port.TargetPort = intstr.FromInt(int(override.TargetPort))
+		}
+		ports = append(ports, port)
+	}
+	return ports, nil
+}
+
+// applyServiceOverride 将 spec.service 中的配置应用到默认生成的 Service
+func (e *KindClient) applyServiceOverride(svc *v1.Service, override nacosgroupv1alpha1.ServiceOverride, defaults []defaultServicePort, field string) error {
+	ports, err := servicePorts(defaults, override.Ports, field)
+	if err != nil {
+		return err
+	}
+	svc.Spec.Ports = ports
+	if override.Type != "" {
+		svc.Spec.Type = override.Type
+	}
+	if override.Annotations != nil {
+		svc.Annotations = e.MergeLabels(e.generateAnnoation(), override.Annotations)
+	}
+	svc.Spec.ExternalTrafficPolicy = override.ExternalTrafficPolicy
+	svc.Spec.LoadBalancerSourceRanges = override.LoadBalancerSourceRanges
+	if override.IPFamilyPolicy != nil {
+		svc.Spec.IPFamilyPolicy = override.IPFamilyPolicy
+	}
+	if len(override.IPFamilies) > 0 {
+		svc.Spec.IPFamilies = override.IPFamilies
+	}
+	return validateService(svc, field)
+}
+
+// validateService 提前拒绝 apiserver 不接受的组合，避免每次调谐都更新失败
+func validateService(svc *v1.Service, field string) error {
+	serviceType := svc.Spec.Type
+	if serviceType == "" {
+		serviceType = v1.ServiceTypeClusterIP
+	}
+	switch serviceType {
+	case v1.ServiceTypeClusterIP, v1.ServiceTypeNodePort, v1.ServiceTypeLoadBalancer:
+	default:
+		return myErrors.New(myErrors.CODE_PARAMETER_ERROR, myErrors.MSG_PARAMETER_ERROT, field+".type", serviceType)
+	}
+	if svc.Spec.ClusterIP == v1.ClusterIPNone && serviceType != v1.ServiceTypeClusterIP {
+		return myErrors.New(myErrors.CODE_PARAMETER_ERROR, myErrors.MSG_PARAMETER_ERROT, field+".type", serviceType)
+	}
+	if serviceType == v1.ServiceTypeClusterIP {
+		if svc.Spec.ExternalTrafficPolicy != "" {
+			return myErrors.New(myErrors.CODE_PARAMETER_ERROR, myErrors.MSG_PARAMETER_ERROT, field+".externalTrafficPolicy", svc.Spec.ExternalTrafficPolicy)
+		}
+		for _, port := range svc.Spec.Ports {
+			if port.NodePort != 0 {
+				return myErrors.New(myErrors.CODE_PARAMETER_ERROR, myErrors.MSG_PARAMETER_ERROT, field+".ports."+port.Name+".nodePort", port.NodePort)
+			}
+		}
+	}
+	if serviceType != v1.ServiceTypeLoadBalancer && len(svc.Spec.LoadBalancerSourceRanges) > 0 {
+		return myErrors.New(myErrors.CODE_PARAMETER_ERROR, myErrors.MSG_PARAMETER_ERROT, field+".loadBalancerSourceRanges", svc.Spec.LoadBalancerSourceRanges)
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package operator
+
+import (
+	"testing"
+
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	nacosgroupv1alpha1 "nacos.io/nacos-operator/api/v1alpha1"
+	myErrors "nacos.io/nacos-operator/pkg/errors"
+)
+
+func TestServicePorts(t *testing.T) {
+	enabled, disabled := true, false
+	ports, err := servicePorts(clientServicePorts, []nacosgroupv1alpha1.ServicePortSpec{
+		{Name: "client", Port: 80, NodePort: 30848},
+		{Name: "rpc", Enabled: &disabled},
+		{Name: "cluster-rpc", Enabled: &enabled},
+		{Name: "metrics", Port: 9090},
+	}, "nacos.Spec.Service.Client")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(ports) != 3 {
+		t.Fatalf("Expected 3 ports, got %+v", ports)
+	}
+	if ports[0].Name != "client" || ports[0].Port != 80 || ports[0].TargetPort.IntValue() != NACOS_PORT || ports[0].NodePort != 30848 {
+		t.Errorf("Unexpected client port %+v", ports[0])
+	}
+	if ports[1].Name != "cluster-rpc" || ports[1].Port != CLUSTER_RPC_PORT {
+		t.Errorf("Expected cluster-rpc enabled, got %+v", ports[1])
+	}
+	if ports[2].Name != "metrics" || ports[2].Port != 9090 {
+		t.Errorf("Expected metrics port appended, got %+v", ports[2])
+	}
+
+	// 默认端口不变，保持已创建 Service 的端口名称
+	defaults := enabledServicePorts(mainServicePorts)
+	if len(defaults) != 3 || defaults[0].Name != "client" || defaults[1].Name != "rpc" || defaults[2].Name != "new-rpc" {
+		t.Errorf("Unexpected default ports %+v", defaults)
+	}
+
+	_, err = servicePorts(clientServicePorts, []nacosgroupv1alpha1.ServicePortSpec{{Name: "extra"}}, "nacos.Spec.Service.Client")
+	if myErr, ok := err.(*myErrors.Err); !ok || myErr.Code != myErrors.CODE_PARAMETER_ERROR {
+		t.Errorf("Expected parameter error for new port without port, got %v", err)
+	}
+}
+
+func TestApplyServiceOverride(t *testing.T) {
+	kindClient := &KindClient{logger: logr.Discard()}
+	newClientService := func() *v1.Service {
+		return &v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-nacos-client", Annotations: map[string]string{"from-cr": "true"}},
+			Spec:       v1.ServiceSpec{Type: v1.ServiceTypeNodePort, Ports: enabledServicePorts(clientServicePorts)},
+		}
+	}
+
+	svc := newClientService()
+	err := kindClient.applyServiceOverride(svc, nacosgroupv1alpha1.ServiceOverride{
+		Type:                     v1.ServiceTypeLoadBalancer,
+		Annotations:              map[string]string{"service.beta.kubernetes.io/alibaba-cloud-loadbalancer-address-type": "intranet"},
+		ExternalTrafficPolicy:    v1.ServiceExternalTrafficPolicyTypeLocal,
+		LoadBalancerSourceRanges: []string{"10.0.0.0/8"},
+	}, clientServicePorts, "nacos.Spec.Service.Client")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if svc.Spec.Type != v1.ServiceTypeLoadBalancer || svc.Spec.ExternalTrafficPolicy != v1.ServiceExternalTrafficPolicyTypeLocal {
+		t.Errorf("Unexpected spec %+v", svc.Spec)
+	}
+	if _, ok := svc.Annotations["from-cr"]; ok || len(svc.Annotations) != 1 {
+		t.Errorf("Expected annotations replaced, got %v", svc.Annotations)
+	}
+
+	tests := []struct {
+		name     string
+		override nacosgroupv1alpha1.ServiceOverride
+		headless bool
+	}{
+		{name: "nodePort on ClusterIP", override: nacosgroupv1alpha1.ServiceOverride{
+			Type:  v1.ServiceTypeClusterIP,
+			Ports: []nacosgroupv1alpha1.ServicePortSpec{{Name: "client", NodePort: 30848}},
+		}},
+		{name: "externalTrafficPolicy on ClusterIP", override: nacosgroupv1alpha1.ServiceOverride{
+			Type: v1.ServiceTypeClusterIP, ExternalTrafficPolicy: v1.ServiceExternalTrafficPolicyTypeLocal,
+		}},
+		{name: "loadBalancerSourceRanges on NodePort", override: nacosgroupv1alpha1.ServiceOverride{
+			LoadBalancerSourceRanges: []string{"10.0.0.0/8"},
+		}},
+		{name: "headless LoadBalancer", headless: true, override: nacosgroupv1alpha1.ServiceOverride{
+			Type: v1.ServiceTypeLoadBalancer,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := newClientService()
+			if tt.headless {
+				svc.Spec.Type = ""
+				svc.Spec.ClusterIP = v1.ClusterIPNone
+			}
+			err := kindClient.applyServiceOverride(svc, tt.override, clientServicePorts, "nacos.Spec.Service.Client")
+			if myErr, ok := err.(*myErrors.Err); !ok || myErr.Code != myErrors.CODE_PARAMETER_ERROR {
+				t.Errorf("Expected parameter error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestRepairServiceExternalTrafficPolicy(t *testing.T) {
+	current := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-nacos-client"},
+		Spec: v1.ServiceSpec{
+			Type:                  v1.ServiceTypeNodePort,
+			ExternalTrafficPolicy: v1.ServiceExternalTrafficPolicyTypeCluster,
+			Ports:                 []v1.ServicePort{{Name: "client", Port: NACOS_PORT, NodePort: 30848}},
+		},
+	}
+	desired := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-nacos-client"},
+		Spec: v1.ServiceSpec{
+			Type:  v1.ServiceTypeNodePort,
+			Ports: []v1.ServicePort{{Name: "client", Port: NACOS_PORT}},
+		},
+	}
+	// apiserver 填充的默认值不算漂移
+	if _, changed := repairService(current, desired); changed {
+		t.Errorf("Expected defaulted externalTrafficPolicy kept")
+	}
+
+	desired.Spec.Type = v1.ServiceTypeClusterIP
+	repaired, changed := repairService(current, desired)
+	if !changed || repaired.Spec.ExternalTrafficPolicy != "" || repaired.Spec.Ports[0].NodePort != 0 {
+		t.Errorf("Expected externalTrafficPolicy and nodePort cleared for ClusterIP, got %+v", repaired.Spec)
+	}
+}
@@ -0,0 +1,102 @@
+package operator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	nacosgroupv1alpha1 "nacos.io/nacos-operator/api/v1alpha1"
+	nacosClient "nacos.io/nacos-operator/pkg/service/nacos"
+)
+
+// 成员上报的 leader 持续不同超过该时间才认定脑裂，避免选举过程中的短暂不一致。
+// 按首次发现的时间计算，与调谐的频率无关
+const splitBrainConfirmDuration = time.Minute
+
+// raftPartitions 按成员在 naming_persistent_service_v2 中上报的 leader 分组，只有一组时返回空。
+// follower 的 term 落后于 leader 是正常的，同一 leader 下的 term 差异不算分裂，分组的 term 取组内最大值。
+// 成员数超过集群半数的分组标记为多数派，结果按成员数从多到少排序
+func raftPartitions(members []nacosgroupv1alpha1.NacosMember) []nacosgroupv1alpha1.RaftPartition {
+	var partitions []nacosgroupv1alpha1.RaftPartition
+	index := map[string]int{}
+	for _, member := range members {
+		leader, term := "", int64(0)
+		for _, group := range member.RaftGroups {
+			if group.Name == nacosClient.RaftGroupNamingPersistentServiceV2 {
+				leader, term = memberHost(group.Leader), group.Term
+			}
+		}
+		i, ok := index[leader]
+		if !ok {
+			i = len(partitions)
+			index[leader] = i
+			partitions = append(partitions, nacosgroupv1alpha1.RaftPartition{Leader: leader})
+		}
+		if term > partitions[i].Term {
+			partitions[i].Term = term
+		}
+		partitions[i].Members = append(partitions[i].Members, member.Address)
+	}
+	if len(partitions) <= 1 {
+		return nil
+	}
+	for i := range partitions {
+		sort.Strings(partitions[i].Members)
+		partitions[i].Majority = len(partitions[i].Members) > len(members)/2
+	}
+	sort.SliceStable(partitions, func(i, j int) bool {
+		if len(partitions[i].Members) != len(partitions[j].Members) {
+			return len(partitions[i].Members) > len(partitions[j].Members)
+		}
+		return partitions[i].Term > partitions[j].Term
+	})
+	return partitions
+}
+
+// observePartitions 根据 status.members 记录首次发现分裂的时间，持续 splitBrainConfirmDuration 后记录 status.partitions 并返回 true
+func observePartitions(nacos *nacosgroupv1alpha1.Nacos, now time.Time) bool {
+	partitions := raftPartitions(nacos.Status.Members)
+	if len(partitions) == 0 {
+		nacos.Status.SplitBrainSince = metav1.Time{}
+		nacos.Status.Partitions = nil
+		return false
+	}
+	if nacos.Status.SplitBrainSince.IsZero() {
+		nacos.Status.SplitBrainSince = metav1.Time{Time: now}
+	}
+	if !splitBrainConfirmed(nacos, now) {
+		return false
+	}
+	nacos.Status.Partitions = partitions
+	return true
+}
+
+// splitBrainConfirmed 分裂已持续 splitBrainConfirmDuration
+func splitBrainConfirmed(nacos *nacosgroupv1alpha1.Nacos, now time.Time) bool {
+	since := nacos.Status.SplitBrainSince
+	return !since.IsZero() && now.Sub(since.Time) >= splitBrainConfirmDuration
+}
+
+// majorityPartition 返回多数派分组，不存在时返回 nil
+func majorityPartition(partitions []nacosgroupv1alpha1.RaftPartition) *nacosgroupv1alpha1.RaftPartition {
+	for i := range partitions {
+		if partitions[i].Majority {
+			return &partitions[i]
+		}
+	}
+	return nil
+}
+
+func describePartitions(partitions []nacosgroupv1alpha1.RaftPartition) string {
+	parts := make([]string, 0, len(partitions))
+	for _, partition := range partitions {
+		leader := partition.Leader
+		if leader == "" {
+			leader = "none"
+		}
+		parts = append(parts, fmt.Sprintf("leader %s term %d: %s", leader, partition.Term, strings.Join(partition.Members, ",")))
+	}
+	return strings.Join(parts, "; ")
+}
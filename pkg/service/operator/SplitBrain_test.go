@@ -0,0 +1,148 @@
+package operator
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	nacosgroupv1alpha1 "nacos.io/nacos-operator/api/v1alpha1"
+	nacosClient "nacos.io/nacos-operator/pkg/service/nacos"
+)
+
+func newPartitionMember(address, podName, leader string, term int64) nacosgroupv1alpha1.NacosMember {
+	return nacosgroupv1alpha1.NacosMember{
+		Address: address,
+		PodName: podName,
+		State:   "UP",
+		RaftGroups: []nacosgroupv1alpha1.RaftGroupStatus{
+			{Name: nacosClient.RaftGroupNamingPersistentServiceV2, Leader: leader, Term: term},
+		},
+	}
+}
+
+func TestRaftPartitions(t *testing.T) {
+	members := []nacosgroupv1alpha1.NacosMember{
+		newPartitionMember("10.0.0.1:8848", "nacos-0", "10.0.0.1:7848", 3),
+		newPartitionMember("10.0.0.2:8848", "nacos-1", "10.0.0.1:7848", 3),
+		newPartitionMember("10.0.0.3:8848", "nacos-2", "10.0.0.1:7848", 3),
+	}
+	if partitions := raftPartitions(members); partitions != nil {
+		t.Fatalf("Expected no partitions for a consistent cluster, got %v", partitions)
+	}
+
+	// nacos-2 自己选出了 leader
+	members[2] = newPartitionMember("10.0.0.3:8848", "nacos-2", "10.0.0.3:7848", 5)
+	partitions := raftPartitions(members)
+	if len(partitions) != 2 {
+		t.Fatalf("Expected 2 partitions, got %d", len(partitions))
+	}
+	if !partitions[0].Majority || partitions[0].Leader != "10.0.0.1" || len(partitions[0].Members) != 2 {
+		t.Errorf("Expected majority partition led by 10.0.0.1 first, got %+v", partitions[0])
+	}
+	if partitions[1].Majority || partitions[1].Term != 5 || partitions[1].Members[0] != "10.0.0.3:8848" {
+		t.Errorf("Expected minority partition of 10.0.0.3, got %+v", partitions[1])
+	}
+	if message := describePartitions(partitions); !strings.Contains(message, "leader 10.0.0.3 term 5: 10.0.0.3:8848") {
+		t.Errorf("Unexpected description: %s", message)
+	}
+
+}
+
+func TestRaftPartitionsIgnoreTermLag(t *testing.T) {
+	// 两个成员的 leader 相同，nacos-2 的 term 落后，nacos-1 已看到更新的 term
+	members := []nacosgroupv1alpha1.NacosMember{
+		newPartitionMember("10.0.0.1:8848", "nacos-0", "10.0.0.1:7848", 3),
+		newPartitionMember("10.0.0.2:8848", "nacos-1", "10.0.0.1:7848", 4),
+		newPartitionMember("10.0.0.3:8848", "nacos-2", "10.0.0.1:7848", 2),
+	}
+	if partitions := raftPartitions(members); partitions != nil {
+		t.Errorf("Expected term lag under the same leader to be ignored, got %v", partitions)
+	}
+
+	// 分组的 term 取组内最大值
+	members[2] = newPartitionMember("10.0.0.3:8848", "nacos-2", "10.0.0.3:7848", 5)
+	partitions := raftPartitions(members)
+	if len(partitions) != 2 || partitions[0].Leader != "10.0.0.1" || partitions[0].Term != 4 || !partitions[0].Majority {
+		t.Errorf("Expected majority partition of 10.0.0.1 with term 4, got %+v", partitions)
+	}
+}
+
+func TestObservePartitions(t *testing.T) {
+	nacos := &nacosgroupv1alpha1.Nacos{}
+	nacos.Status.Members = []nacosgroupv1alpha1.NacosMember{
+		newPartitionMember("10.0.0.1:8848", "nacos-0", "10.0.0.1:7848", 3),
+		newPartitionMember("10.0.0.2:8848", "nacos-1", "10.0.0.1:7848", 3),
+		newPartitionMember("10.0.0.3:8848", "nacos-2", "10.0.0.3:7848", 5),
+	}
+
+	// 分裂持续一段时间后才记录，与检查次数无关
+	now := time.Now()
+	for i := 0; i < 5; i++ {
+		if observePartitions(nacos, now.Add(time.Duration(i)*time.Second)) || nacos.Status.Partitions != nil {
+			t.Fatalf("Expected check %d not to confirm split brain, got %v", i, nacos.Status.Partitions)
+		}
+	}
+	if !nacos.Status.SplitBrainSince.Time.Equal(now) {
+		t.Errorf("Expected split brain to be first seen at %v, got %v", now, nacos.Status.SplitBrainSince)
+	}
+	if !observePartitions(nacos, now.Add(splitBrainConfirmDuration)) || len(nacos.Status.Partitions) != 2 {
+		t.Fatalf("Expected split brain to be confirmed, got %v", nacos.Status.Partitions)
+	}
+
+	// 恢复一致后清空
+	nacos.Status.Members[2] = newPartitionMember("10.0.0.3:8848", "nacos-2", "10.0.0.1:7848", 3)
+	if observePartitions(nacos, now.Add(2*splitBrainConfirmDuration)) || nacos.Status.Partitions != nil || !nacos.Status.SplitBrainSince.IsZero() {
+		t.Errorf("Expected split brain to be cleared, got %v %v", nacos.Status.SplitBrainSince, nacos.Status.Partitions)
+	}
+}
+
+func TestRaftResetCandidate(t *testing.T) {
+	now := time.Now()
+	replicas := int32(3)
+	nacos := &nacosgroupv1alpha1.Nacos{Spec: nacosgroupv1alpha1.NacosSpec{Replicas: &replicas}}
+	nacos.Status.Members = []nacosgroupv1alpha1.NacosMember{
+		newPartitionMember("10.0.0.1:8848", "nacos-0", "10.0.0.1:7848", 3),
+		newPartitionMember("10.0.0.2:8848", "nacos-1", "10.0.0.1:7848", 3),
+		newPartitionMember("10.0.0.3:8848", "nacos-2", "10.0.0.3:7848", 5),
+	}
+	nacos.Status.Partitions = raftPartitions(nacos.Status.Members)
+	nacos.Status.SplitBrainSince = metav1.Time{Time: now.Add(-splitBrainConfirmDuration)}
+
+	ready := []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+	pods := []corev1.Pod{
+		newHealPod("nacos-0", "10.0.0.1"),
+		newHealPod("nacos-1", "10.0.0.2"),
+		newHealPod("nacos-2", "10.0.0.3"),
+	}
+	for i := range pods {
+		pods[i].Status.Conditions = ready
+	}
+
+	pod, reason := raftResetCandidate(nacos, pods, now)
+	if pod != "nacos-2" || !strings.Contains(reason, "majority leader 10.0.0.1") {
+		t.Fatalf("Expected minority member nacos-2 to be reset, got %q %q", pod, reason)
+	}
+
+	// 脑裂未确认时不处理
+	nacos.Status.SplitBrainSince = metav1.Time{Time: now.Add(-splitBrainConfirmDuration / 2)}
+	if pod, _ := raftResetCandidate(nacos, pods, now); pod != "" {
+		t.Errorf("Expected no reset before split brain is confirmed, got %s", pod)
+	}
+	nacos.Status.SplitBrainSince = metav1.Time{Time: now.Add(-splitBrainConfirmDuration)}
+
+	// 有 Pod 未就绪时等待
+	pods[0].Status.Conditions = nil
+	if pod, _ := raftResetCandidate(nacos, pods, now); pod != "" {
+		t.Errorf("Expected to wait for pods to be ready, got %s", pod)
+	}
+	pods[0].Status.Conditions = ready
+
+	// 没有多数派时不处理
+	nacos.Status.Members[1] = newPartitionMember("10.0.0.2:8848", "nacos-1", "10.0.0.2:7848", 4)
+	nacos.Status.Partitions = raftPartitions(nacos.Status.Members)
+	if pod, _ := raftResetCandidate(nacos, pods, now); pod != "" {
+		t.Errorf("Expected no reset without a majority, got %s", pod)
+	}
+}
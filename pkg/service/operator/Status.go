@@ -5,9 +5,12 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"fmt"
 	"time"
 
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	log "github.com/go-logr/logr"
@@ -20,44 +23,118 @@ type IStatusClient interface {
 }
 
 type StatusClient struct {
-	logger log.Logger
-	client client.Client
+	logger   log.Logger
+	client   client.Client
+	recorder record.EventRecorder
 }
 
-func NewStatusClient(logger log.Logger, k8sService k8s.Services, client client.Client) *StatusClient {
+func NewStatusClient(logger log.Logger, k8sService k8s.Services, client client.Client, recorder record.EventRecorder) *StatusClient {
 	return &StatusClient{
-		client: client,
-		logger: logger,
+		client:   client,
+		logger:   logger,
+		recorder: recorder,
 	}
 }
 
+// 标准状态条件类型
+const (
+	ConditionReady         = "Ready"
+	ConditionAvailable     = "Available"
+	ConditionProgressing   = "Progressing"
+	ConditionDegraded      = "Degraded"
+	ConditionDatabaseReady = "DatabaseReady"
+	ConditionConfigSynced  = "ConfigSynced"
+	// PVC 容量与 spec.volume 一致，不支持的修改（缩容、修改 storageClassName）为 False
+	ConditionStorageSynced = "StorageSynced"
+	ConditionAdminRotated  = "AdminRotated"
+	// 各 Raft group 的 leader 与成员一致
+	ConditionRaftConsistent = "RaftConsistent"
+	// 灾难恢复等待确认时存在运行中的成员可用于重建集群
+	ConditionRecoverable = "Recoverable"
+	// 最近一次金丝雀检查通过
+	ConditionCanaryPassed = "CanaryPassed"
+)
+
 // 更新状态
-func (c *StatusClient) UpdateStatusRunning(nacos *nacosgroupv1alpha1.Nacos) {
+func (c *StatusClient) UpdateStatusRunning(nacos *nacosgroupv1alpha1.Nacos) error {
 	c.updateLastEvent(nacos, 200, "", true)
-	nacos.Status.Phase = nacosgroupv1alpha1.PhaseRunning
+	c.setPhase(nacos, nacosgroupv1alpha1.PhaseRunning, "all nacos members are up")
+	c.SetCondition(nacos, ConditionReady, metav1.ConditionTrue, "Running", "all nacos members are up")
+	switch upgrade := nacos.Status.Upgrade; upgrade.Phase {
+	case UpgradeUpgrading:
+		c.SetCondition(nacos, ConditionProgressing, metav1.ConditionTrue, "Upgrading", fmt.Sprintf("upgrading %s to %s", upgrade.PodName, upgrade.ToImage))
+	case UpgradePaused:
+		c.SetCondition(nacos, ConditionProgressing, metav1.ConditionFalse, ReasonUpgradePaused, upgrade.Message)
+	default:
+		c.SetCondition(nacos, ConditionProgressing, metav1.ConditionFalse, "ReconcileComplete", "")
+	}
+	c.SetCondition(nacos, ConditionDegraded, metav1.ConditionFalse, "Healthy", "")
 	c.syncHealthy(nacos)
 	c.syncVersionDigest(nacos)
-	myErrors.EnsureNormal(c.client.Status().Update(context.TODO(), nacos))
+	c.syncObservedGeneration(nacos)
+	return c.client.Status().Update(context.TODO(), nacos)
 }
 
 // 更新状态
-func (c *StatusClient) UpdateStatus(nacos *nacosgroupv1alpha1.Nacos) {
+func (c *StatusClient) UpdateStatus(nacos *nacosgroupv1alpha1.Nacos) error {
 	c.syncHealthy(nacos)
 	c.syncVersionDigest(nacos)
-	myErrors.EnsureNormal(c.client.Status().Update(context.TODO(), nacos))
+	c.syncObservedGeneration(nacos)
+	return c.client.Status().Update(context.TODO(), nacos)
 }
 
-func (c *StatusClient) UpdateExceptionStatus(nacos *nacosgroupv1alpha1.Nacos, err *myErrors.Err) {
+func (c *StatusClient) UpdateExceptionStatus(nacos *nacosgroupv1alpha1.Nacos, err *myErrors.Err) error {
 	c.updateLastEvent(nacos, err.Code, err.Msg, false)
 	// 设置为异常状态
-	nacos.Status.Phase = nacosgroupv1alpha1.PhaseFailed
+	c.setPhase(nacos, nacosgroupv1alpha1.PhaseFailed, err.Msg)
+	reason := errorConditionReason(err.Code)
+	c.SetCondition(nacos, ConditionReady, metav1.ConditionFalse, reason, err.Msg)
+	c.SetCondition(nacos, ConditionDegraded, metav1.ConditionTrue, reason, err.Msg)
 	c.syncHealthy(nacos)
 	c.syncVersionDigest(nacos)
-	e := c.client.Status().Update(context.TODO(), nacos)
-	if e != nil {
-		c.logger.V(-1).Info(e.Error())
+	c.syncObservedGeneration(nacos)
+	return c.client.Status().Update(context.TODO(), nacos)
+}
+
+// SetCondition 设置状态条件，status 未变化时保留原 LastTransitionTime，需调用 Update* 持久化
+func (c *StatusClient) SetCondition(nacos *nacosgroupv1alpha1.Nacos, conditionType string, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&nacos.Status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		ObservedGeneration: nacos.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// errorConditionReason 根据错误码生成 condition reason
+func errorConditionReason(code int) string {
+	switch code {
+	case myErrors.CODE_PARAMETER_ERROR:
+		return "InvalidSpec"
+	case myErrors.CODE_CLUSTER_FAILE:
+		return "ClusterUnhealthy"
+	case myErrors.CODE_SPLIT_BRAIN:
+		return "SplitBrain"
+	case myErrors.CODE_QUORUM_LOST:
+		return "QuorumLost"
+	case myErrors.CODE_ERR_SYSTEM:
+		return "SystemError"
+	default:
+		return "ReconcileError"
 	}
+}
 
+// setPhase 设置 Phase，发生变化时记录 Kubernetes Event
+func (c *StatusClient) setPhase(nacos *nacosgroupv1alpha1.Nacos, phase nacosgroupv1alpha1.Phase, message string) {
+	if nacos.Status.Phase == phase {
+		return
+	}
+	c.logger.Info("phase changed", "nacos", nacos.Name, "from", nacos.Status.Phase, "to", phase)
+	nacos.Status.Phase = phase
+	if reason, ok := phaseEventReasons[phase]; ok {
+		c.recorder.Event(nacos, phaseEventType(phase), reason, message)
+	}
 }
 
 const EVENT_MAX_SIZE = 10
@@ -107,6 +184,10 @@ func (c *StatusClient) syncHealthy(nacos *nacosgroupv1alpha1.Nacos) {
 	nacos.Status.Healthy = nacos.Status.Phase == nacosgroupv1alpha1.PhaseRunning
 }
 
+func (c *StatusClient) syncObservedGeneration(nacos *nacosgroupv1alpha1.Nacos) {
+	nacos.Status.ObservedGeneration = nacos.Generation
+}
+
 const versionDigestLength = 10
 
 func (c *StatusClient) syncVersionDigest(nacos *nacosgroupv1alpha1.Nacos) {
@@ -0,0 +1,108 @@
+package operator
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	appv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+
+	"nacos.io/nacos-operator/pkg/service/k8s"
+	"nacos.io/nacos-operator/pkg/util/hash"
+)
+
+// 模板各字段的 hash，用于在模板变化时找出变化的字段
+const TemplateFieldHashesAnnotation = "nacos.io/template-field-hashes"
+
+// templateFieldHashes 按字段计算渲染后 Pod 模板的 hash：metadata 与 spec 的每个字段一项，
+// 容器按 name 展开到每个字段，如 spec.containers[nacos].image
+func templateFieldHashes(template *v1.PodTemplateSpec) (map[string]string, error) {
+	data, err := json.Marshal(template)
+	if err != nil {
+		return nil, err
+	}
+	var object map[string]map[string]interface{}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, err
+	}
+
+	hashes := map[string]string{}
+	add := func(path string, value interface{}) error {
+		// encoding/json 按 key 排序输出 map，结果与字段顺序无关
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		hashes[path] = shortHash(string(data))
+		return nil
+	}
+	for key, value := range object["metadata"] {
+		if err := add("metadata."+key, value); err != nil {
+			return nil, err
+		}
+	}
+	for key, value := range object["spec"] {
+		containers, ok := value.([]interface{})
+		if !ok || (key != "containers" && key != "initContainers" && key != "ephemeralContainers") {
+			if err := add("spec."+key, value); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		for _, container := range containers {
+			fields, _ := container.(map[string]interface{})
+			for field, value := range fields {
+				if err := add(fmt.Sprintf("spec.%s[%v].%s", key, fields["name"], field), value); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return hashes, nil
+}
+
+// setTemplateHash 在 StatefulSet 上记录模板的 hash，k8s.StatefulSet 在 hash 不同时更新 StatefulSet
+func setTemplateHash(ss *appv1.StatefulSet) (map[string]string, error) {
+	hashes, err := templateFieldHashes(&ss.Spec.Template)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(hashes)
+	if err != nil {
+		return nil, err
+	}
+	annotations := map[string]string{}
+	for k, v := range ss.Annotations {
+		annotations[k] = v
+	}
+	annotations[k8s.TemplateHashAnnotation] = shortHash(string(data))
+	annotations[TemplateFieldHashesAnnotation] = string(data)
+	ss.Annotations = annotations
+	return hashes, nil
+}
+
+// templateChangedFields 返回与上次记录相比变化的字段，没有记录时返回 nil
+func templateChangedFields(current *appv1.StatefulSet, hashes map[string]string) []string {
+	previous := map[string]string{}
+	if err := json.Unmarshal([]byte(current.Annotations[TemplateFieldHashesAnnotation]), &previous); err != nil || len(previous) == 0 {
+		return nil
+	}
+	var fields []string
+	for path, value := range hashes {
+		if previous[path] != value {
+			fields = append(fields, path)
+		}
+	}
+	for path := range previous {
+		if _, ok := hashes[path]; !ok {
+			fields = append(fields, path)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+func shortHash(data string) string {
+	return hash.ComputeSHA256(data)[:16]
+}
@@ -0,0 +1,86 @@
+package operator
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+	nacosgroupv1alpha1 "nacos.io/nacos-operator/api/v1alpha1"
+	"nacos.io/nacos-operator/pkg/service/k8s"
+)
+
+func TestEnsureStatefulSetTemplateDrift(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = nacosgroupv1alpha1.AddToScheme(scheme)
+	fakeClient := fake.NewSimpleClientset()
+	recorder := record.NewFakeRecorder(10)
+	kindClient := &KindClient{
+		k8sService: k8s.NewK8sService(fakeClient, nil, logr.Discard()),
+		logger:     logr.Discard(),
+		scheme:     scheme,
+		recorder:   recorder,
+	}
+	replicas := int32(1)
+	nacos := &nacosgroupv1alpha1.Nacos{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-nacos", Namespace: "default", UID: "test-uid", Annotations: map[string]string{"team": "infra"}},
+		Spec: nacosgroupv1alpha1.NacosSpec{
+			Type:     TYPE_STAND_ALONE,
+			Image:    "nacos/nacos-server:v2.3.2",
+			Replicas: &replicas,
+		},
+	}
+	getStatefulSet := func() *v1.PodSpec {
+		ss, err := fakeClient.AppsV1().StatefulSets("default").Get(context.TODO(), "test-nacos", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if ss.Annotations[k8s.TemplateHashAnnotation] == "" || ss.Annotations["team"] != "infra" {
+			t.Errorf("Expected template hash recorded with CR annotations, got %v", ss.Annotations)
+		}
+		return &ss.Spec.Template.Spec
+	}
+
+	if err := kindClient.EnsureStatefulset(nacos); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, ok := nacos.Annotations[k8s.TemplateHashAnnotation]; ok {
+		t.Errorf("Expected CR annotations untouched")
+	}
+	getStatefulSet()
+
+	// 渲染结果不变时不更新
+	fakeClient.ClearActions()
+	if err := kindClient.EnsureStatefulset(nacos); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, action := range fakeClient.Actions() {
+		if action.GetVerb() == "update" {
+			t.Errorf("Expected no update for an unchanged template")
+		}
+	}
+
+	// 旧实现忽略的字段
+	nacos.Spec.Image = "nacos/nacos-server:v2.4.0"
+	nacos.Spec.Tolerations = []v1.Toleration{{Key: "dedicated", Operator: v1.TolerationOpExists}}
+	if err := kindClient.EnsureStatefulset(nacos); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	spec := getStatefulSet()
+	if spec.Containers[0].Image != "nacos/nacos-server:v2.4.0" || len(spec.Tolerations) != 1 {
+		t.Errorf("Expected image and tolerations updated, got %s %v", spec.Containers[0].Image, spec.Tolerations)
+	}
+	select {
+	case event := <-recorder.Events:
+		if !strings.Contains(event, ReasonTemplateChanged) || !strings.Contains(event, "spec.containers[test-nacos].image, spec.tolerations") {
+			t.Errorf("Unexpected event %s", event)
+		}
+	default:
+		t.Errorf("Expected TemplateChanged event")
+	}
+}
@@ -0,0 +1,190 @@
+package operator
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	nacosgroupv1alpha1 "nacos.io/nacos-operator/api/v1alpha1"
+	nacosClient "nacos.io/nacos-operator/pkg/service/nacos"
+)
+
+// Raft group 中的角色
+const (
+	RaftRoleLeader   = "leader"
+	RaftRoleFollower = "follower"
+	RaftRoleUnknown  = "unknown"
+)
+
+// buildMembers 根据节点列表生成 status.members，按地址排序
+func buildMembers(servers []nacosClient.ServerInfo, pods []corev1.Pod) []nacosgroupv1alpha1.NacosMember {
+	members := make([]nacosgroupv1alpha1.NacosMember, 0, len(servers))
+	for _, server := range servers {
+		member := nacosgroupv1alpha1.NacosMember{
+			Address:        server.Address,
+			PodName:        matchPod(server, pods),
+			State:          server.State,
+			Version:        server.ExtendInfo.Version,
+			FailAccessCnt:  server.FailAccessCnt,
+			ReadyToUpgrade: server.ExtendInfo.ReadyToUpgrade,
+			Abilities: nacosgroupv1alpha1.MemberAbilities{
+				SupportRemoteConnection: server.Abilities.RemoteAbility.SupportRemoteConnection,
+				SupportRemoteMetrics:    server.Abilities.ConfigAbility.SupportRemoteMetrics,
+				SupportJraft:            server.Abilities.NamingAbility.SupportJraft,
+			},
+		}
+		for name, group := range server.RaftGroups() {
+			member.RaftGroups = append(member.RaftGroups, nacosgroupv1alpha1.RaftGroupStatus{
+				Name:    name,
+				Role:    raftRole(server, group.Leader),
+				Leader:  group.Leader,
+				Term:    int64(group.Term),
+				Members: group.RaftGroupMember,
+			})
+		}
+		sort.Slice(member.RaftGroups, func(i, j int) bool {
+			return member.RaftGroups[i].Name < member.RaftGroups[j].Name
+		})
+		members = append(members, member)
+	}
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].Address < members[j].Address
+	})
+	return members
+}
+
+// matchPod 通过 Pod IP 或主机名（Pod 名称为第一段域名）匹配节点对应的 Pod
+func matchPod(server nacosClient.ServerInfo, pods []corev1.Pod) string {
+	host := memberHost(server.Address)
+	if host == "" {
+		host = server.IP
+	}
+	for _, pod := range pods {
+		if podHasIP(pod, host) || podHasIP(pod, server.IP) {
+			return pod.Name
+		}
+		if strings.Split(host, ".")[0] == pod.Name {
+			return pod.Name
+		}
+	}
+	return ""
+}
+
+// podHasIP 比较解析后的地址，IPv6 的不同写法视为相同；双栈时匹配任一协议族的地址
+func podHasIP(pod corev1.Pod, address string) bool {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return false
+	}
+	if podIP := net.ParseIP(pod.Status.PodIP); podIP != nil && podIP.Equal(ip) {
+		return true
+	}
+	for _, podIP := range pod.Status.PodIPs {
+		if parsed := net.ParseIP(podIP.IP); parsed != nil && parsed.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// raftRole 节点地址与 leader 的主机部分相同即为 leader（leader 使用 raft 端口）
+func raftRole(server nacosClient.ServerInfo, leader string) string {
+	if leader == "" {
+		return RaftRoleUnknown
+	}
+	host := memberHost(server.Address)
+	if host == "" {
+		host = server.IP
+	}
+	if memberHost(leader) == host {
+		return RaftRoleLeader
+	}
+	return RaftRoleFollower
+}
+
+// memberHost 去掉地址中的端口，兼容 IPv6
+func memberHost(address string) string {
+	if host, _, err := net.SplitHostPort(address); err == nil {
+		return host
+	}
+	return strings.Trim(address, "[]")
+}
+
+// raftIssues 检测 Raft 拓扑的不一致：同一个 group 内节点上报的 leader 或成员不同，
+// 或者不同 group 的成员不同。各 group 独立选举，leader 不同是正常的，不记录
+func raftIssues(members []nacosgroupv1alpha1.NacosMember) []string {
+	var issues []string
+	leaders := map[string]map[string]bool{}
+	memberSets := map[string]map[string]bool{}
+	for _, member := range members {
+		for _, group := range member.RaftGroups {
+			if leaders[group.Name] == nil {
+				leaders[group.Name] = map[string]bool{}
+				memberSets[group.Name] = map[string]bool{}
+			}
+			if group.Leader != "" {
+				leaders[group.Name][memberHost(group.Leader)] = true
+			}
+			if len(group.Members) > 0 {
+				memberSets[group.Name][memberSetKey(group.Members)] = true
+			}
+		}
+	}
+
+	groups := make([]string, 0, len(leaders))
+	for name := range leaders {
+		groups = append(groups, name)
+	}
+	sort.Strings(groups)
+
+	groupMembers := map[string]string{}
+	for _, name := range groups {
+		if len(leaders[name]) > 1 {
+			issues = append(issues, fmt.Sprintf("raft group %s has different leaders: %s", name, strings.Join(sortedKeys(leaders[name]), ",")))
+		}
+		if len(memberSets[name]) > 1 {
+			issues = append(issues, fmt.Sprintf("raft group %s has different members: %s", name, strings.Join(sortedKeys(memberSets[name]), " / ")))
+		} else if len(memberSets[name]) == 1 {
+			groupMembers[name] = sortedKeys(memberSets[name])[0]
+		}
+	}
+
+	if distinct := distinctValues(groupMembers); len(distinct) > 1 {
+		issues = append(issues, fmt.Sprintf("raft groups disagree on members: %s", formatGroups(groupMembers)))
+	}
+	return issues
+}
+
+func memberSetKey(members []string) string {
+	sorted := append([]string{}, members...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ",")
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func distinctValues(m map[string]string) map[string]bool {
+	values := map[string]bool{}
+	for _, v := range m {
+		values[v] = true
+	}
+	return values
+}
+
+func formatGroups(m map[string]string) string {
+	parts := make([]string, 0, len(m))
+	for name, v := range m {
+		parts = append(parts, name+"="+v)
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, " ")
+}
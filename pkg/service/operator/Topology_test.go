@@ -0,0 +1,129 @@
+package operator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	nacosgroupv1alpha1 "nacos.io/nacos-operator/api/v1alpha1"
+	nacosClient "nacos.io/nacos-operator/pkg/service/nacos"
+)
+
+func newServerInfo(address, leader string, term int, members ...string) nacosClient.ServerInfo {
+	server := nacosClient.ServerInfo{Address: address, State: "UP"}
+	server.ExtendInfo.Version = "2.1.0"
+	group := nacosClient.RaftGroup{Leader: leader, Term: term, RaftGroupMember: members}
+	server.ExtendInfo.RaftMetaData.MetaDataMap.NamingPersistentServiceV2 = group
+	server.ExtendInfo.RaftMetaData.MetaDataMap.NamingInstanceMetadata = group
+	server.ExtendInfo.RaftMetaData.MetaDataMap.NamingServiceMetadata = group
+	return server
+}
+
+func TestBuildMembers(t *testing.T) {
+	raftMembers := []string{"nacos-0.nacos-headless:7848", "nacos-1.nacos-headless:7848"}
+	servers := []nacosClient.ServerInfo{
+		newServerInfo("nacos-1.nacos-headless:8848", "nacos-0.nacos-headless:7848", 3, raftMembers...),
+		newServerInfo("nacos-0.nacos-headless:8848", "nacos-0.nacos-headless:7848", 3, raftMembers...),
+	}
+	pods := []corev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "nacos-0"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "nacos-1"}},
+	}
+
+	members := buildMembers(servers, pods)
+	if len(members) != 2 {
+		t.Fatalf("Expected 2 members, got %d", len(members))
+	}
+	if members[0].PodName != "nacos-0" || members[1].PodName != "nacos-1" {
+		t.Errorf("Expected members sorted and matched to pods, got %s, %s", members[0].PodName, members[1].PodName)
+	}
+	if len(members[0].RaftGroups) != 3 {
+		t.Fatalf("Expected 3 raft groups, got %d", len(members[0].RaftGroups))
+	}
+	for _, group := range members[0].RaftGroups {
+		if group.Role != RaftRoleLeader || group.Term != 3 {
+			t.Errorf("Expected nacos-0 to lead %s at term 3, got %s/%d", group.Name, group.Role, group.Term)
+		}
+	}
+	for _, group := range members[1].RaftGroups {
+		if group.Role != RaftRoleFollower {
+			t.Errorf("Expected nacos-1 to follow in %s, got %s", group.Name, group.Role)
+		}
+	}
+	if issues := raftIssues(members); len(issues) != 0 {
+		t.Errorf("Expected consistent topology, got %v", issues)
+	}
+}
+
+func TestRaftIssues(t *testing.T) {
+	raftMembers := []string{"nacos-0:7848", "nacos-1:7848", "nacos-2:7848"}
+
+	// 同一个 group 内节点上报的 leader 不同
+	split := buildMembers([]nacosClient.ServerInfo{
+		newServerInfo("nacos-0:8848", "nacos-0:7848", 2, raftMembers...),
+		newServerInfo("nacos-1:8848", "nacos-0:7848", 2, raftMembers...),
+		newServerInfo("nacos-2:8848", "nacos-2:7848", 5, raftMembers...),
+	}, nil)
+	issues := raftIssues(split)
+	if len(issues) == 0 || !strings.Contains(issues[0], "different leaders") {
+		t.Errorf("Expected leader disagreement inside a group, got %v", issues)
+	}
+
+	// 各 group 独立选举，不同 group 的 leader 不同不是问题
+	server := newServerInfo("nacos-0:8848", "nacos-0:7848", 2, raftMembers...)
+	server.ExtendInfo.RaftMetaData.MetaDataMap.NamingServiceMetadata.Leader = "nacos-1:7848"
+	if issues = raftIssues(buildMembers([]nacosClient.ServerInfo{server}, nil)); len(issues) != 0 {
+		t.Errorf("Expected no issue for different leaders across groups, got %v", issues)
+	}
+
+	// 不同 group 的成员不同
+	server = newServerInfo("nacos-0:8848", "nacos-0:7848", 2, raftMembers...)
+	server.ExtendInfo.RaftMetaData.MetaDataMap.NamingInstanceMetadata.RaftGroupMember = raftMembers[:2]
+	issues = raftIssues(buildMembers([]nacosClient.ServerInfo{server}, nil))
+	if len(issues) != 1 || !strings.Contains(issues[0], "disagree on members") {
+		t.Errorf("Expected membership disagreement across groups, got %v", issues)
+	}
+}
+
+func TestCheckRaftTopologyRecordsTransitionOnce(t *testing.T) {
+	raftMembers := []string{"nacos-0:7848", "nacos-1:7848"}
+	recorder := record.NewFakeRecorder(10)
+	client := &OperatorClient{StatusClient: NewStatusClient(logr.Discard(), nil, nil, recorder), Recorder: recorder}
+	nacos := &nacosgroupv1alpha1.Nacos{ObjectMeta: metav1.ObjectMeta{Name: "nacos"}}
+	nacos.Status.Members = buildMembers([]nacosClient.ServerInfo{
+		newServerInfo("nacos-0:8848", "nacos-0:7848", 2, raftMembers...),
+		newServerInfo("nacos-1:8848", "nacos-1:7848", 2, raftMembers...),
+	}, nil)
+
+	// 连续多次调谐只在条件变为 False 时记录一次事件
+	for i := 0; i < 3; i++ {
+		client.checkRaftTopology(nacos)
+	}
+	if !meta.IsStatusConditionFalse(nacos.Status.Conditions, ConditionRaftConsistent) {
+		t.Fatalf("Expected RaftConsistent to be False, got %v", nacos.Status.Conditions)
+	}
+	if len(recorder.Events) != 1 {
+		t.Errorf("Expected 1 %s event, got %d", ReasonRaftInconsistent, len(recorder.Events))
+	}
+}
+
+func TestMatchPodIPv6(t *testing.T) {
+	pods := []corev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "nacos-0"}, Status: corev1.PodStatus{
+			PodIP:  "10.244.0.1",
+			PodIPs: []corev1.PodIP{{IP: "10.244.0.1"}, {IP: "fd00:10:244::1"}},
+		}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "nacos-1"}, Status: corev1.PodStatus{PodIP: "fd00:10:244::2"}},
+	}
+	// 不同写法的 IPv6 地址，以及双栈 Pod 的第二个地址
+	if got := matchPod(newServerInfo("[fd00:10:244:0::2]:8848", "", 0), pods); got != "nacos-1" {
+		t.Errorf("Expected nacos-1, got %q", got)
+	}
+	if got := matchPod(newServerInfo("[fd00:10:244::1]:8848", "", 0), pods); got != "nacos-0" {
+		t.Errorf("Expected nacos-0, got %q", got)
+	}
+}
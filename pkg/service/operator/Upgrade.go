@@ -0,0 +1,222 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	log "github.com/go-logr/logr"
+	appv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	nacosgroupv1alpha1 "nacos.io/nacos-operator/api/v1alpha1"
+	"nacos.io/nacos-operator/pkg/service/k8s"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// 分批升级阶段，记录在 status.upgrade.phase
+const (
+	// 等待 status.upgrade.podName 以新版本重新加入集群
+	UpgradeUpgrading = "Upgrading"
+	// 成员超时未重新加入集群，等待注解继续
+	UpgradePaused = "Paused"
+)
+
+// 继续暂停的升级的注解，值任意，由 operator 移除
+const UpgradeResumeAnnotation = "nacos.io/upgrade-resume"
+
+// 默认单个成员重新加入集群的超时时间
+const DefaultUpgradeMemberTimeout = time.Minute * 10
+
+// 升级一个成员后重新入队的间隔
+const upgradeRequeueInterval = time.Second * 10
+
+type UpgradeClient struct {
+	k8sService k8s.Services
+	client     client.Client
+	logger     log.Logger
+	recorder   record.EventRecorder
+}
+
+func NewUpgradeClient(logger log.Logger, k8sService k8s.Services, client client.Client, recorder record.EventRecorder) *UpgradeClient {
+	return &UpgradeClient{
+		k8sService: k8sService,
+		client:     client,
+		logger:     logger,
+		recorder:   recorder,
+	}
+}
+
+// upgrading 存在进行中或暂停的升级
+func upgrading(nacos *nacosgroupv1alpha1.Nacos) bool {
+	return nacos.Status.Upgrade.Phase != ""
+}
+
+// upgradePartition StatefulSet 的 rollingUpdate.partition，没有升级时为 0
+func upgradePartition(nacos *nacosgroupv1alpha1.Nacos) int32 {
+	if upgrading(nacos) {
+		return nacos.Status.Upgrade.Partition
+	}
+	return 0
+}
+
+func upgradeMemberTimeout(nacos *nacosgroupv1alpha1.Nacos) time.Duration {
+	if nacos.Spec.Upgrade.MemberTimeoutSeconds > 0 {
+		return time.Duration(nacos.Spec.Upgrade.MemberTimeoutSeconds) * time.Second
+	}
+	return DefaultUpgradeMemberTimeout
+}
+
+// Upgrade 在渲染 StatefulSet 前检测镜像变化并开始升级，处理成员超时与继续
+func (c *UpgradeClient) Upgrade(nacos *nacosgroupv1alpha1.Nacos, now time.Time) error {
+	upgrade := &nacos.Status.Upgrade
+	if nacos.Spec.Type != TYPE_CLUSTER || nacos.Spec.Upgrade.Disabled || recovering(nacos) {
+		if upgrading(nacos) {
+			c.logger.Info("upgrade orchestration skipped, roll out all members", "nacos", nacos.Name, "phase", upgrade.Phase)
+			*upgrade = nacosgroupv1alpha1.UpgradeStatus{}
+		}
+		return nil
+	}
+	ss, err := c.k8sService.GetStatefulSet(nacos.Namespace, nacos.Name)
+	if err != nil {
+		// 新建的 StatefulSet 直接使用 spec.image
+		if k8sErrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	image := containerImage(ss, nacos.Name)
+	switch {
+	case image == "", upgrade.Phase == "" && scalingDown(nacos):
+		// 缩容完成后再开始升级
+		return nil
+	case upgrade.Phase == "" && image != nacos.Spec.Image,
+		// 升级过程中再次修改镜像（包括回滚）时，以当前模板的镜像重新开始
+		upgrade.Phase != "" && upgrade.ToImage != nacos.Spec.Image:
+		c.start(nacos, image, now)
+	case upgrade.Phase == UpgradePaused:
+		if _, ok := nacos.Annotations[UpgradeResumeAnnotation]; !ok {
+			return nil
+		}
+		if err := c.removeResume(nacos); err != nil {
+			return err
+		}
+		upgrade.Phase = UpgradeUpgrading
+		upgrade.MemberStartTime = metav1.Time{Time: now}
+		upgrade.Message = ""
+		c.recorder.Eventf(nacos, v1.EventTypeNormal, ReasonUpgradeResumed, "resume upgrading %s to %s", upgrade.PodName, upgrade.ToImage)
+	case upgrade.Phase == UpgradeUpgrading:
+		timeout := upgradeMemberTimeout(nacos)
+		if now.Sub(upgrade.MemberStartTime.Time) < timeout {
+			return nil
+		}
+		upgrade.Phase = UpgradePaused
+		upgrade.Message = fmt.Sprintf("%s did not rejoin the cluster with %s within %s", upgrade.PodName, upgrade.ToImage, timeout)
+		c.recorder.Eventf(nacos, v1.EventTypeWarning, ReasonUpgradePaused, "%s, annotate %s to resume", upgrade.Message, UpgradeResumeAnnotation)
+	}
+	return nil
+}
+
+// start 从最大序号的成员开始升级
+func (c *UpgradeClient) start(nacos *nacosgroupv1alpha1.Nacos, from string, now time.Time) {
+	partition := *nacos.Spec.Replicas - 1
+	if partition < 0 {
+		partition = 0
+	}
+	nacos.Status.Upgrade = nacosgroupv1alpha1.UpgradeStatus{
+		Phase:           UpgradeUpgrading,
+		FromImage:       from,
+		ToImage:         nacos.Spec.Image,
+		Partition:       partition,
+		PodName:         fmt.Sprintf("%s-%d", nacos.Name, partition),
+		MemberStartTime: metav1.Time{Time: now},
+	}
+	c.recorder.Eventf(nacos, v1.EventTypeNormal, ReasonUpgradeStarted, "upgrade %d members from %s to %s, starting with %s",
+		*nacos.Spec.Replicas, from, nacos.Spec.Image, nacos.Status.Upgrade.PodName)
+}
+
+// MarkHealthy 集群检查通过后确认正在升级的成员已重新加入集群，返回 true 表示开始升级下一个成员或升级完成
+func (c *UpgradeClient) MarkHealthy(nacos *nacosgroupv1alpha1.Nacos, pods []v1.Pod, now time.Time) (bool, error) {
+	upgrade := &nacos.Status.Upgrade
+	if upgrade.Phase != UpgradeUpgrading {
+		return false, nil
+	}
+	ss, err := c.k8sService.GetStatefulSet(nacos.Namespace, nacos.Name)
+	if err != nil {
+		return false, err
+	}
+	if !memberUpgraded(nacos, ss, pods) {
+		return false, nil
+	}
+	c.recorder.Eventf(nacos, v1.EventTypeNormal, ReasonMemberUpgraded, "%s rejoined the cluster with %s", upgrade.PodName, upgrade.ToImage)
+	if upgrade.Partition == 0 {
+		c.recorder.Eventf(nacos, v1.EventTypeNormal, ReasonUpgradeCompleted, "upgraded %d members from %s to %s", *nacos.Spec.Replicas, upgrade.FromImage, upgrade.ToImage)
+		*upgrade = nacosgroupv1alpha1.UpgradeStatus{}
+		return true, nil
+	}
+	upgrade.Partition--
+	upgrade.PodName = fmt.Sprintf("%s-%d", nacos.Name, upgrade.Partition)
+	upgrade.MemberStartTime = metav1.Time{Time: now}
+	return true, nil
+}
+
+// memberUpgraded 成员的 Pod 已按 StatefulSet 的新版本重建，并以目标版本、readyToUpgrade 重新加入集群
+func memberUpgraded(nacos *nacosgroupv1alpha1.Nacos, ss *appv1.StatefulSet, pods []v1.Pod) bool {
+	upgrade := nacos.Status.Upgrade
+	// StatefulSet controller 处理新模板前 updateRevision 仍是旧版本
+	if ss.Status.ObservedGeneration < ss.Generation {
+		return false
+	}
+	recreated := false
+	for _, pod := range pods {
+		if pod.Name == upgrade.PodName {
+			recreated = ss.Status.UpdateRevision == "" || pod.Labels[appv1.ControllerRevisionHashLabelKey] == ss.Status.UpdateRevision
+		}
+	}
+	if !recreated {
+		return false
+	}
+	for _, member := range nacos.Status.Members {
+		if member.PodName == upgrade.PodName {
+			return member.ReadyToUpgrade && versionMatches(member.Version, upgrade.ToImage)
+		}
+	}
+	return false
+}
+
+// versionMatches 成员上报的版本与镜像 tag 是否一致，tag 不是版本号（如 latest 或 digest）时不比较
+func versionMatches(version string, image string) bool {
+	tag := strings.TrimPrefix(imageVersion(image), "v")
+	if tag == "" || tag[0] < '0' || tag[0] > '9' {
+		return true
+	}
+	version = strings.TrimPrefix(version, "v")
+	// 如 v2.3.2-slim 对应 2.3.2
+	return version != "" && (tag == version || strings.HasPrefix(tag, version+"-"))
+}
+
+// containerImage 返回 StatefulSet 中 Nacos 容器的镜像
+func containerImage(ss *appv1.StatefulSet, name string) string {
+	for _, container := range ss.Spec.Template.Spec.Containers {
+		if container.Name == name {
+			return container.Image
+		}
+	}
+	return ""
+}
+
+// removeResume 移除继续升级的注解，避免下次暂停时直接继续
+func (c *UpgradeClient) removeResume(nacos *nacosgroupv1alpha1.Nacos) error {
+	obj := nacos.DeepCopy()
+	patch := client.MergeFrom(obj.DeepCopy())
+	delete(obj.Annotations, UpgradeResumeAnnotation)
+	if err := c.client.Patch(context.TODO(), obj, patch); err != nil {
+		return err
+	}
+	delete(nacos.Annotations, UpgradeResumeAnnotation)
+	nacos.ResourceVersion = obj.ResourceVersion
+	return nil
+}
@@ -0,0 +1,131 @@
+package operator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	appv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	nacosgroupv1alpha1 "nacos.io/nacos-operator/api/v1alpha1"
+)
+
+func TestUpgradeStartPauseResume(t *testing.T) {
+	nacos := newTestNacos("nacos")
+	nacos.Spec.Image = "nacos/nacos-server:v2.4.0"
+	ss := newTestStatefulSet(nacos)
+	ss.Spec.Template.Spec.Containers[0].Image = "nacos/nacos-server:v2.3.2"
+	ss.Status.UpdateRevision = "nacos-new"
+	kindClient, _ := newTestKindClient(nacos, ss)
+	client := NewUpgradeClient(logr.Discard(), kindClient.k8sService, kindClient.client, kindClient.recorder)
+	recorder := kindClient.recorder.(*record.FakeRecorder)
+	now := time.Now()
+
+	if err := client.Upgrade(nacos, now); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	upgrade := nacos.Status.Upgrade
+	if upgrade.Phase != UpgradeUpgrading || upgrade.FromImage != "nacos/nacos-server:v2.3.2" || upgrade.Partition != 2 || upgrade.PodName != "nacos-2" {
+		t.Fatalf("Expected the upgrade to start with nacos-2, got %+v", upgrade)
+	}
+	if upgradePartition(nacos) != 2 {
+		t.Errorf("Expected partition 2, got %d", upgradePartition(nacos))
+	}
+	expectEvent(t, recorder, ReasonUpgradeStarted)
+
+	nacos.Spec.Upgrade.MemberTimeoutSeconds = 60
+	if err := client.Upgrade(nacos, now.Add(time.Second*30)); err != nil || nacos.Status.Upgrade.Phase != UpgradeUpgrading {
+		t.Fatalf("Expected to wait for the member, got %+v %v", nacos.Status.Upgrade, err)
+	}
+	if err := client.Upgrade(nacos, now.Add(time.Minute)); err != nil || nacos.Status.Upgrade.Phase != UpgradePaused {
+		t.Fatalf("Expected the upgrade to pause, got %+v %v", nacos.Status.Upgrade, err)
+	}
+	expectEvent(t, recorder, ReasonUpgradePaused)
+
+	// 暂停时保持 partition，等待注解继续
+	if err := client.Upgrade(nacos, now.Add(time.Hour)); err != nil || nacos.Status.Upgrade.Phase != UpgradePaused || upgradePartition(nacos) != 2 {
+		t.Fatalf("Expected the upgrade to stay paused, got %+v %v", nacos.Status.Upgrade, err)
+	}
+	nacos.Annotations = map[string]string{UpgradeResumeAnnotation: "true"}
+	if err := client.Upgrade(nacos, now.Add(time.Hour)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if nacos.Status.Upgrade.Phase != UpgradeUpgrading || !nacos.Status.Upgrade.MemberStartTime.Time.Equal(now.Add(time.Hour)) {
+		t.Errorf("Expected the upgrade to resume, got %+v", nacos.Status.Upgrade)
+	}
+	if _, ok := nacos.Annotations[UpgradeResumeAnnotation]; ok {
+		t.Errorf("Expected the resume annotation to be removed")
+	}
+	expectEvent(t, recorder, ReasonUpgradeResumed)
+
+	// 关闭分批升级时清除进度，由 StatefulSet 更新所有成员
+	nacos.Spec.Upgrade.Disabled = true
+	if err := client.Upgrade(nacos, now); err != nil || upgrading(nacos) || upgradePartition(nacos) != 0 {
+		t.Errorf("Expected the upgrade to be cleared, got %+v %v", nacos.Status.Upgrade, err)
+	}
+}
+
+func TestUpgradeMarkHealthyMemberByMember(t *testing.T) {
+	nacos := newTestNacos("nacos")
+	nacos.Spec.Image = "nacos/nacos-server:v2.4.0"
+	ss := newTestStatefulSet(nacos)
+	ss.Spec.Template.Spec.Containers[0].Image = "nacos/nacos-server:v2.4.0"
+	ss.Status.UpdateRevision = "nacos-new"
+	kindClient, _ := newTestKindClient(nacos, ss)
+	client := NewUpgradeClient(logr.Discard(), kindClient.k8sService, kindClient.client, kindClient.recorder)
+	recorder := kindClient.recorder.(*record.FakeRecorder)
+	nacos.Status.Upgrade = nacosgroupv1alpha1.UpgradeStatus{
+		Phase: UpgradeUpgrading, FromImage: "nacos/nacos-server:v2.3.2", ToImage: "nacos/nacos-server:v2.4.0", Partition: 2, PodName: "nacos-2",
+	}
+	pods := []v1.Pod{}
+	for _, name := range []string{"nacos-0", "nacos-1", "nacos-2"} {
+		pods = append(pods, v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{appv1.ControllerRevisionHashLabelKey: "nacos-old"}}})
+		nacos.Status.Members = append(nacos.Status.Members, nacosgroupv1alpha1.NacosMember{PodName: name, Version: "2.3.2", ReadyToUpgrade: true})
+	}
+	rejoin := func(i int) {
+		pods[i].Labels[appv1.ControllerRevisionHashLabelKey] = "nacos-new"
+		nacos.Status.Members[i].Version = "2.4.0"
+	}
+
+	// 成员仍是旧版本
+	if upgraded, err := client.MarkHealthy(nacos, pods, time.Now()); err != nil || upgraded {
+		t.Fatalf("Expected to wait for nacos-2, got %v %v", upgraded, err)
+	}
+	for i := 2; i >= 0; i-- {
+		rejoin(i)
+		upgraded, err := client.MarkHealthy(nacos, pods, time.Now())
+		if err != nil || !upgraded {
+			t.Fatalf("Expected %s to be upgraded, got %v %v", pods[i].Name, upgraded, err)
+		}
+		expectEvent(t, recorder, ReasonMemberUpgraded)
+		if i > 0 && (nacos.Status.Upgrade.Partition != int32(i-1) || nacos.Status.Upgrade.PodName != pods[i-1].Name) {
+			t.Errorf("Expected to continue with %s, got %+v", pods[i-1].Name, nacos.Status.Upgrade)
+		}
+	}
+	if upgrading(nacos) {
+		t.Errorf("Expected the upgrade to complete, got %+v", nacos.Status.Upgrade)
+	}
+	expectEvent(t, recorder, ReasonUpgradeCompleted)
+}
+
+func TestVersionMatches(t *testing.T) {
+	tests := []struct {
+		version string
+		image   string
+		want    bool
+	}{
+		{"2.4.0", "nacos/nacos-server:v2.4.0", true},
+		{"2.4.0", "nacos/nacos-server:2.4.0-slim", true},
+		{"2.3.2", "nacos/nacos-server:v2.4.0", false},
+		{"", "nacos/nacos-server:v2.4.0", false},
+		{"2.3.2", "nacos/nacos-server:latest", true},
+		{"2.3.2", "registry:5000/nacos/nacos-server", true},
+	}
+	for _, tt := range tests {
+		if got := versionMatches(tt.version, tt.image); got != tt.want {
+			t.Errorf("versionMatches(%q, %q) = %v, want %v", tt.version, tt.image, got, tt.want)
+		}
+	}
+}
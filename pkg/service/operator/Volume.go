@@ -0,0 +1,158 @@
+package operator
+
+import (
+	"fmt"
+
+	appv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	nacosgroupv1alpha1 "nacos.io/nacos-operator/api/v1alpha1"
+	myErrors "nacos.io/nacos-operator/pkg/errors"
+)
+
+// StorageSynced 条件的 reason，spec 中的存储修改不受支持
+const StorageUnsupportedChange = "UnsupportedChange"
+
+// buildVolumeClaimTemplate 返回 StatefulSet 的 volumeClaimTemplate，HostPath 或 EmptyDir 优先时返回 nil
+func buildVolumeClaimTemplate(nacos *nacosgroupv1alpha1.Nacos) *v1.PersistentVolumeClaim {
+	if nacos.Spec.Volume.VolumeClaimTemplate == nil || nacos.Spec.Volume.HostPath != nil || nacos.Spec.Volume.EmptyDir != nil {
+		return nil
+	}
+	pvc := nacos.Spec.Volume.VolumeClaimTemplate.DeepCopy()
+	if pvc.Name == "" {
+		pvc.Name = "db"
+	}
+	if len(pvc.Spec.AccessModes) == 0 {
+		pvc.Spec.AccessModes = []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce}
+	}
+	if nacos.Spec.Volume.PersistentVolumeSize != "" {
+		if pvc.Spec.Resources.Requests == nil {
+			pvc.Spec.Resources.Requests = v1.ResourceList{}
+		}
+		pvc.Spec.Resources.Requests[v1.ResourceStorage] = resource.MustParse(nacos.Spec.Volume.PersistentVolumeSize)
+	}
+	return pvc
+}
+
+// EnsureVolumeExpansion 在 StatefulSet 重建前扩容已有的 PVC。volumeClaimTemplates 不能原地修改，
+// 扩容后 StatefulSet 以 orphan 方式删除并重建，Pod 不受影响；缩容、修改 storageClassName
+// 以及 StorageClass 不支持扩容时返回参数错误，StatefulSet 保留现有的 volumeClaimTemplates
+func (e *KindClient) EnsureVolumeExpansion(nacos *nacosgroupv1alpha1.Nacos) error {
+	desired := buildVolumeClaimTemplate(nacos)
+	if desired == nil {
+		return nil
+	}
+	current, err := e.k8sService.GetStatefulSet(nacos.Namespace, e.generateName(nacos))
+	if err != nil {
+		if k8sErrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	var template *v1.PersistentVolumeClaim
+	for i := range current.Spec.VolumeClaimTemplates {
+		if current.Spec.VolumeClaimTemplates[i].Name == desired.Name {
+			template = &current.Spec.VolumeClaimTemplates[i]
+		}
+	}
+	// 从其他存储方式切换或修改模板名称时使用新的 PVC，不需要扩容
+	if template == nil {
+		return nil
+	}
+
+	if class := desired.Spec.StorageClassName; class != nil && (template.Spec.StorageClassName == nil || *template.Spec.StorageClassName != *class) {
+		return myErrors.New(myErrors.CODE_PARAMETER_ERROR, myErrors.MSG_PARAMETER_ERROT, "nacos.Spec.Volume.volumeClaimTemplate.storageClassName",
+			fmt.Sprintf("%s, changing the storageClassName of existing volumes (%s) is not supported", *class, storageClassName(template)))
+	}
+	size, ok := desired.Spec.Resources.Requests[v1.ResourceStorage]
+	if !ok {
+		return nil
+	}
+	currentSize := template.Spec.Resources.Requests[v1.ResourceStorage]
+	switch size.Cmp(currentSize) {
+	case 0:
+		return nil
+	case -1:
+		return myErrors.New(myErrors.CODE_PARAMETER_ERROR, myErrors.MSG_PARAMETER_ERROT, "nacos.Spec.Volume.persistentVolumeSize",
+			fmt.Sprintf("%s, shrinking volumes from %s is not supported", size.String(), currentSize.String()))
+	}
+
+	pvcs, err := e.listVolumeClaims(nacos)
+	if err != nil {
+		return err
+	}
+	// 先检查所有 PVC 的 StorageClass，避免只扩容了一部分
+	var expanding []v1.PersistentVolumeClaim
+	checked := map[string]bool{}
+	for _, pvc := range pvcs {
+		requested := pvc.Spec.Resources.Requests[v1.ResourceStorage]
+		if requested.Cmp(size) >= 0 {
+			continue
+		}
+		class := storageClassName(&pvc)
+		if _, ok := checked[class]; !ok {
+			allowed, err := e.allowVolumeExpansion(class)
+			if err != nil {
+				return err
+			}
+			checked[class] = allowed
+		}
+		if !checked[class] {
+			return myErrors.New(myErrors.CODE_PARAMETER_ERROR, myErrors.MSG_PARAMETER_ERROT, "nacos.Spec.Volume.persistentVolumeSize",
+				fmt.Sprintf("%s, storageClass %q of %s does not allow volume expansion", size.String(), class, pvc.Name))
+		}
+		expanding = append(expanding, pvc)
+	}
+	for _, pvc := range expanding {
+		from := pvc.Spec.Resources.Requests[v1.ResourceStorage]
+		if pvc.Spec.Resources.Requests == nil {
+			pvc.Spec.Resources.Requests = v1.ResourceList{}
+		}
+		pvc.Spec.Resources.Requests[v1.ResourceStorage] = size
+		if err := e.k8sService.UpdatePersistentVolumeClaim(nacos.Namespace, &pvc); err != nil {
+			return err
+		}
+		e.recorder.Eventf(nacos, v1.EventTypeNormal, ReasonVolumeExpanded, "expand persistentvolumeclaim %s from %s to %s", pvc.Name, from.String(), size.String())
+	}
+	return nil
+}
+
+// allowVolumeExpansion StorageClass 是否允许扩容；namespace 级别的 RBAC 无法读取 StorageClass 时
+// 交给 apiserver 校验 PVC 的修改
+func (e *KindClient) allowVolumeExpansion(name string) (bool, error) {
+	if name == "" {
+		return false, nil
+	}
+	class, err := e.k8sService.GetStorageClass(name)
+	if err != nil {
+		if k8sErrors.IsForbidden(err) {
+			e.logger.Info("can't get storageclass, leave the expansion check to apiserver", "storageClass", name, "error", err.Error())
+			return true, nil
+		}
+		if k8sErrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return class.AllowVolumeExpansion != nil && *class.AllowVolumeExpansion, nil
+}
+
+func storageClassName(pvc *v1.PersistentVolumeClaim) string {
+	if pvc.Spec.StorageClassName == nil {
+		return ""
+	}
+	return *pvc.Spec.StorageClassName
+}
+
+// keepVolumeClaimTemplates 存储修改不受支持时使用现有的 volumeClaimTemplates 渲染 StatefulSet，
+// 避免 StatefulSet 被删除重建，其余字段照常更新
+func keepVolumeClaimTemplates(nacos *nacosgroupv1alpha1.Nacos, current, ss *appv1.StatefulSet) {
+	condition := meta.FindStatusCondition(nacos.Status.Conditions, ConditionStorageSynced)
+	if current == nil || condition == nil || condition.Reason != StorageUnsupportedChange {
+		return
+	}
+	ss.Spec.VolumeClaimTemplates = current.Spec.VolumeClaimTemplates
+}
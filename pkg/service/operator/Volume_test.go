@@ -0,0 +1,117 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+	nacosgroupv1alpha1 "nacos.io/nacos-operator/api/v1alpha1"
+	myErrors "nacos.io/nacos-operator/pkg/errors"
+)
+
+func claimSize(t *testing.T, fakeClient *fake.Clientset, name string) string {
+	pvc, err := fakeClient.CoreV1().PersistentVolumeClaims("default").Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return pvc.Spec.Resources.Requests.Storage().String()
+}
+
+func TestEnsureVolumeExpansion(t *testing.T) {
+	nacos := newTestNacos("test-nacos")
+	nacos.Spec.Volume = nacosgroupv1alpha1.Storage{VolumeClaimTemplate: &v1.PersistentVolumeClaim{}, PersistentVolumeSize: "20Gi"}
+	kindClient, fakeClient := newTestKindClient(nil, newTestClaims(nacos, true)...)
+	if err := kindClient.EnsureVolumeExpansion(nacos); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if size := claimSize(t, fakeClient, fmt.Sprintf("db-test-nacos-%d", i)); size != "20Gi" {
+			t.Errorf("Expected db-test-nacos-%d expanded to 20Gi, got %s", i, size)
+		}
+	}
+	if size := claimSize(t, fakeClient, "db-other-0"); size != "10Gi" {
+		t.Errorf("Expected claims of other statefulsets untouched, got %s", size)
+	}
+	if events := len(kindClient.recorder.(*record.FakeRecorder).Events); events != 3 {
+		t.Errorf("Expected 3 VolumeExpanded events, got %d", events)
+	}
+
+	// 大小不变时不做修改
+	nacos.Spec.Volume.PersistentVolumeSize = "10Gi"
+	if err := kindClient.EnsureVolumeExpansion(nacos); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestEnsureVolumeExpansionRejected(t *testing.T) {
+	tests := []struct {
+		name           string
+		size           string
+		storageClass   string
+		allowExpansion bool
+	}{
+		{name: "shrink", size: "5Gi", allowExpansion: true},
+		{name: "storage class swap", size: "10Gi", storageClass: "fast", allowExpansion: true},
+		{name: "expansion not allowed", size: "20Gi"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nacos := newTestNacos("test-nacos")
+			nacos.Spec.Volume = nacosgroupv1alpha1.Storage{VolumeClaimTemplate: &v1.PersistentVolumeClaim{}, PersistentVolumeSize: tt.size}
+			if tt.storageClass != "" {
+				nacos.Spec.Volume.VolumeClaimTemplate.Spec.StorageClassName = &tt.storageClass
+			}
+			kindClient, fakeClient := newTestKindClient(nil, newTestClaims(nacos, tt.allowExpansion)...)
+			err := kindClient.EnsureVolumeExpansion(nacos)
+			if myErr, ok := err.(*myErrors.Err); !ok || myErr.Code != myErrors.CODE_PARAMETER_ERROR {
+				t.Errorf("Expected parameter error, got %v", err)
+			}
+			for i := 0; i < 3; i++ {
+				if size := claimSize(t, fakeClient, fmt.Sprintf("db-test-nacos-%d", i)); size != "10Gi" {
+					t.Errorf("Expected db-test-nacos-%d untouched, got %s", i, size)
+				}
+			}
+		})
+	}
+}
+
+func TestEnsureStatefulsetKeepsUnsupportedVolumeChange(t *testing.T) {
+	nacos := newTestNacos("test-nacos")
+	nacos.Spec.Volume = nacosgroupv1alpha1.Storage{VolumeClaimTemplate: &v1.PersistentVolumeClaim{}, PersistentVolumeSize: "5Gi"}
+	kindClient, fakeClient := newTestKindClient(nil, newTestClaims(nacos, true)...)
+	nacos.Spec.Type = TYPE_STAND_ALONE
+	replicas := int32(1)
+	nacos.Spec.Replicas = &replicas
+	current, _ := fakeClient.AppsV1().StatefulSets("default").Get(context.TODO(), "test-nacos", metav1.GetOptions{})
+	current.Spec.Replicas = &replicas
+	current.Spec.Template.Spec.Containers = []v1.Container{{Name: "test-nacos", Image: "nacos/nacos-server:v2.3.1"}}
+	_, _ = fakeClient.AppsV1().StatefulSets("default").Update(context.TODO(), current, metav1.UpdateOptions{})
+
+	// 缩容不受支持，StatefulSet 以现有的 volumeClaimTemplates 更新，不会被删除重建
+	err := kindClient.EnsureVolumeExpansion(nacos)
+	myErr, ok := err.(*myErrors.Err)
+	if !ok || myErr.Code != myErrors.CODE_PARAMETER_ERROR {
+		t.Fatalf("Expected parameter error, got %v", err)
+	}
+	meta.SetStatusCondition(&nacos.Status.Conditions, metav1.Condition{
+		Type: ConditionStorageSynced, Status: metav1.ConditionFalse, Reason: StorageUnsupportedChange, Message: myErr.Msg,
+	})
+	if err := kindClient.EnsureStatefulset(nacos); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	ss, err := fakeClient.AppsV1().StatefulSets("default").Get(context.TODO(), "test-nacos", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected the statefulset to be kept, got %v", err)
+	}
+	if len(ss.Spec.VolumeClaimTemplates) != 1 || ss.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests.Storage().String() != "10Gi" {
+		t.Errorf("Expected the current volumeClaimTemplates, got %v", ss.Spec.VolumeClaimTemplates)
+	}
+	if containerImage(ss, "test-nacos") != nacos.Spec.Image {
+		t.Errorf("Expected the pod template to be updated, got %s", containerImage(ss, "test-nacos"))
+	}
+}
@@ -0,0 +1,114 @@
+package operator
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	appv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+	nacosgroupv1alpha1 "nacos.io/nacos-operator/api/v1alpha1"
+	"nacos.io/nacos-operator/pkg/service/k8s"
+	ctrlfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// newTestNacos default 命名空间下 3 副本的集群模式 CR
+func newTestNacos(name string) *nacosgroupv1alpha1.Nacos {
+	replicas := int32(3)
+	return &nacosgroupv1alpha1.Nacos{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: "test-uid"},
+		Spec: nacosgroupv1alpha1.NacosSpec{
+			Type:     TYPE_CLUSTER,
+			Image:    "nacos/nacos-server:v2.3.2",
+			Replicas: &replicas,
+		},
+	}
+}
+
+// newTestKindClient objects 放入 fake clientset，nacos 不为 nil 时放入 controller-runtime 的 fake client
+func newTestKindClient(nacos *nacosgroupv1alpha1.Nacos, objects ...runtime.Object) (*KindClient, *fake.Clientset) {
+	scheme := runtime.NewScheme()
+	_ = nacosgroupv1alpha1.AddToScheme(scheme)
+	builder := ctrlfake.NewClientBuilder().WithScheme(scheme)
+	if nacos != nil {
+		builder = builder.WithObjects(nacos)
+	}
+	fakeClient := fake.NewSimpleClientset(objects...)
+	service := k8s.NewK8sService(fakeClient, nil, logr.Discard())
+	return NewKindClient(logr.Discard(), service, scheme, builder.Build(), record.NewFakeRecorder(10)), fakeClient
+}
+
+// newTestStatefulSet 与 CR 同名的 StatefulSet，选择 newTestPod 创建的 Pod
+func newTestStatefulSet(nacos *nacosgroupv1alpha1.Nacos) *appv1.StatefulSet {
+	return &appv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: nacos.Name, Namespace: nacos.Namespace},
+		Spec: appv1.StatefulSetSpec{
+			Replicas: nacos.Spec.Replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "nacos"}},
+			Template: v1.PodTemplateSpec{Spec: v1.PodSpec{
+				Containers: []v1.Container{{Name: "nacos", Image: nacos.Spec.Image}},
+			}},
+		},
+	}
+}
+
+// newTestPod 创建 StatefulSet 的 Pod，running 为 Nacos 容器是否运行
+func newTestPod(name string, running, ready bool) *v1.Pod {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"app": "nacos"}},
+		Status:     v1.PodStatus{Phase: v1.PodPending},
+	}
+	if running {
+		pod.Status.Phase = v1.PodRunning
+		pod.Status.ContainerStatuses = []v1.ContainerStatus{{Name: "nacos", State: v1.ContainerState{Running: &v1.ContainerStateRunning{}}}}
+	}
+	status := v1.ConditionFalse
+	if ready {
+		status = v1.ConditionTrue
+	}
+	pod.Status.Conditions = []v1.PodCondition{{Type: v1.PodReady, Status: status}}
+	return pod
+}
+
+// newTestClaims 名为 db 的卷模板及 3 个成员的 10Gi PVC，另有一个其他 StatefulSet 的 PVC
+func newTestClaims(nacos *nacosgroupv1alpha1.Nacos, allowExpansion bool) []runtime.Object {
+	class := "standard"
+	claim := func(name string) v1.PersistentVolumeClaim {
+		return v1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: nacos.Namespace},
+			Spec: v1.PersistentVolumeClaimSpec{
+				StorageClassName: &class,
+				Resources:        v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceStorage: resource.MustParse("10Gi")}},
+			},
+		}
+	}
+	ss := &appv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: nacos.Name, Namespace: nacos.Namespace},
+		Spec:       appv1.StatefulSetSpec{VolumeClaimTemplates: []v1.PersistentVolumeClaim{claim("db")}},
+	}
+	objects := []runtime.Object{ss, &storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: class}, AllowVolumeExpansion: &allowExpansion}}
+	for i := 0; i < 3; i++ {
+		pvc := claim(fmt.Sprintf("db-%s-%d", nacos.Name, i))
+		objects = append(objects, &pvc)
+	}
+	other := claim("db-other-0")
+	return append(objects, &other)
+}
+
+func expectEvent(t *testing.T, recorder *record.FakeRecorder, reason string) {
+	select {
+	case event := <-recorder.Events:
+		if !strings.Contains(event, reason) {
+			t.Errorf("Expected %s event, got %s", reason, event)
+		}
+	default:
+		t.Errorf("Expected %s event", reason)
+	}
+}
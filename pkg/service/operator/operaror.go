@@ -1,9 +1,18 @@
 package operator
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	log "github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 	nacosgroupv1alpha1 "nacos.io/nacos-operator/api/v1alpha1"
 	myErrors "nacos.io/nacos-operator/pkg/errors"
 	"nacos.io/nacos-operator/pkg/service/k8s"
@@ -18,107 +27,367 @@ type IOperatorClient interface {
 }
 
 type OperatorClient struct {
-    KindClient   *KindClient
-    CheckClient  *CheckClient
-    HealClient   *HealClient
-    StatusClient *StatusClient
-    PGClient     *PGClient
+	KindClient     *KindClient
+	CheckClient    *CheckClient
+	HealClient     *HealClient
+	RecoveryClient *RecoveryClient
+	UpgradeClient  *UpgradeClient
+	ScaleClient    *ScaleClient
+	StatusClient   *StatusClient
+	PGClient       *PGClient
+	Recorder       record.EventRecorder
 }
 
-func NewOperatorClient(logger log.Logger, clientset kubernetes.Interface, s *runtime.Scheme, client client.Client) *OperatorClient {
-	service := k8s.NewK8sService(clientset, logger)
-    return &OperatorClient{
+func NewOperatorClient(logger log.Logger, clientset kubernetes.Interface, restConfig *rest.Config, s *runtime.Scheme, client client.Client, recorder record.EventRecorder) *OperatorClient {
+	service := k8s.NewK8sService(clientset, restConfig, logger)
+	kindClient := NewKindClient(logger, service, s, client, recorder)
+	checkClient := NewCheckClient(logger, service, client)
+	return &OperatorClient{
 		// 资源客户端
-		KindClient: NewKindClient(logger, service, s),
-        // 检测客户端
-        CheckClient: NewCheckClient(logger, service, client),
+		KindClient: kindClient,
+		// 检测客户端
+		CheckClient: checkClient,
 		// 状态客户端
-		StatusClient: NewStatusClient(logger, service, client),
+		StatusClient: NewStatusClient(logger, service, client, recorder),
 		// 维护客户端
-        HealClient: NewHealClient(logger, service),
-        PGClient:   NewPGClient(logger, client),
-    }
+		HealClient: NewHealClient(logger, service, recorder),
+		// 灾难恢复客户端
+		RecoveryClient: NewRecoveryClient(logger, service, kindClient, client, recorder),
+		// 分批升级客户端
+		UpgradeClient: NewUpgradeClient(logger, service, client, recorder),
+		// 缩容客户端
+		ScaleClient: NewScaleClient(logger, service, kindClient, checkClient, recorder),
+		PGClient:    NewPGClient(logger, client),
+		Recorder:    recorder,
+	}
 }
 
-func (c *OperatorClient) MakeEnsure(nacos *nacosgroupv1alpha1.Nacos) {
+func (c *OperatorClient) MakeEnsure(nacos *nacosgroupv1alpha1.Nacos) StepResult {
 	// 验证CR字段
 	c.KindClient.ValidationField(nacos)
+	if err := validateNetwork(nacos); err != nil {
+		return ResultFromError(err)
+	}
 
+	var ensures []func(nacos *nacosgroupv1alpha1.Nacos) error
 	switch nacos.Spec.Type {
 	case TYPE_STAND_ALONE:
-		c.KindClient.EnsureConfigmap(nacos)
-		c.KindClient.EnsureStatefulset(nacos)
-		c.KindClient.EnsureService(nacos)
-		// also expose client ports via NodePort service in standalone mode
-		c.KindClient.EnsureClientService(nacos)
-		if nacos.Spec.Database.TypeDatabase == "mysql" && nacos.Spec.MysqlInitImage != "" {
-			c.KindClient.EnsureMysqlConfigMap(nacos)
-			c.KindClient.EnsureJob(nacos)
+		ensures = []func(nacos *nacosgroupv1alpha1.Nacos) error{
+			c.KindClient.EnsureStatefulset,
+			c.KindClient.EnsureService,
+			// also expose client ports via NodePort service in standalone mode
+			c.KindClient.EnsureClientService,
+			// 从集群模式切换时删除 PodDisruptionBudget
+			c.KindClient.EnsurePodDisruptionBudget,
 		}
 	case TYPE_CLUSTER:
-		c.KindClient.EnsureConfigmap(nacos)
-		c.KindClient.EnsureStatefulsetCluster(nacos)
-		c.KindClient.EnsureHeadlessServiceCluster(nacos)
-		c.KindClient.EnsureClientService(nacos)
-		if nacos.Spec.Database.TypeDatabase == "mysql" && nacos.Spec.MysqlInitImage != "" {
-			c.KindClient.EnsureMysqlConfigMap(nacos)
-			c.KindClient.EnsureJob(nacos)
+		ensures = []func(nacos *nacosgroupv1alpha1.Nacos) error{
+			c.KindClient.EnsureStatefulsetCluster,
+			c.KindClient.EnsureHeadlessServiceCluster,
+			c.KindClient.EnsureClientService,
+			c.KindClient.EnsurePodDisruptionBudget,
 		}
 	default:
-		panic(myErrors.New(myErrors.CODE_PARAMETER_ERROR, myErrors.MSG_PARAMETER_ERROT, "nacos.Spec.Type", nacos.Spec.Type))
+		return Terminal(myErrors.New(myErrors.CODE_PARAMETER_ERROR, myErrors.MSG_PARAMETER_ERROT, "nacos.Spec.Type", nacos.Spec.Type))
+	}
+	ensures = append(ensures, c.KindClient.EnsureConsoleIngress, c.KindClient.EnsureConsoleHTTPRoute)
+	if nacos.Spec.Database.TypeDatabase == "mysql" && nacos.Spec.MysqlInitImage != "" {
+		ensures = append(ensures, c.KindClient.EnsureMysqlConfigMap, c.KindClient.EnsureJob)
+	}
+
+	// 配置先于 StatefulSet 生成，StatefulSet 模板需要使用配置的 digest
+	if err := c.KindClient.EnsureConfigmap(nacos); err != nil {
+		c.StatusClient.SetCondition(nacos, ConditionConfigSynced, metav1.ConditionFalse, "SyncFailed", err.Error())
+		return ResultFromError(err)
 	}
+	c.StatusClient.SetCondition(nacos, ConditionConfigSynced, metav1.ConditionTrue, "Synced", configSyncedMessage(nacos))
+
+	// PVC 扩容先于 StatefulSet 重建；不支持的修改只记录条件，StatefulSet 保留现有的 volumeClaimTemplates
+	if err := c.KindClient.EnsureVolumeExpansion(nacos); err != nil {
+		myErr, ok := err.(*myErrors.Err)
+		if !ok || myErr.Code != myErrors.CODE_PARAMETER_ERROR {
+			c.StatusClient.SetCondition(nacos, ConditionStorageSynced, metav1.ConditionFalse, "ExpansionFailed", err.Error())
+			return ResultFromError(err)
+		}
+		c.StatusClient.SetCondition(nacos, ConditionStorageSynced, metav1.ConditionFalse, StorageUnsupportedChange, myErr.Msg)
+	} else if pvc := buildVolumeClaimTemplate(nacos); pvc != nil {
+		c.StatusClient.SetCondition(nacos, ConditionStorageSynced, metav1.ConditionTrue, "Synced",
+			fmt.Sprintf("volumeClaimTemplate %s requests %s", pvc.Name, pvc.Spec.Resources.Requests.Storage().String()))
+	} else {
+		meta.RemoveStatusCondition(&nacos.Status.Conditions, ConditionStorageSynced)
+	}
+
+	for _, ensure := range ensures {
+		if err := ensure(nacos); err != nil {
+			return ResultFromError(err)
+		}
+	}
+	return Continue()
 }
 
-func (c *OperatorClient) PreCheck(nacos *nacosgroupv1alpha1.Nacos) {
+func (c *OperatorClient) PreCheck(nacos *nacosgroupv1alpha1.Nacos) StepResult {
 	switch nacos.Status.Phase {
 	case nacosgroupv1alpha1.PhaseFailed:
 		// 失败，需要修复
-		c.HealClient.MakeHeal(nacos)
+		if err := c.HealClient.MakeHeal(nacos); err != nil {
+			return ResultFromError(err)
+		}
 	case nacosgroupv1alpha1.PhaseNone:
 		// 初始化
-		nacos.Status.Phase = nacosgroupv1alpha1.PhaseCreating
+		c.StatusClient.setPhase(nacos, nacosgroupv1alpha1.PhaseCreating, "nacos is being created")
+		c.StatusClient.SetCondition(nacos, ConditionReady, metav1.ConditionFalse, "Creating", "nacos is being created")
+		c.StatusClient.SetCondition(nacos, ConditionProgressing, metav1.ConditionTrue, "Creating", "nacos is being created")
 		nacos.Status.Healthy = false
-		panic(myErrors.New(myErrors.CODE_NORMAL, ""))
+		if err := c.StatusClient.UpdateStatus(nacos); err != nil {
+			return Fail(err)
+		}
+		return Requeue(initRequeueInterval)
 	case nacosgroupv1alpha1.PhaseScale:
 	default:
 		// TODO
 	}
+	return Continue()
 }
 
 // PGEnsure: 在确保 K8s 资源前进行 PG 连通性校验与初始化
-func (c *OperatorClient) PGEnsure(nacos *nacosgroupv1alpha1.Nacos) {
-    // 未配置 Postgres 则跳过
-    if nacos.Spec.Postgres.Host == "" {
-        return
-    }
-    // 若显式关闭初始化，直接跳过
-    if !nacos.Spec.PGInit.Enabled {
-        return
-    }
-    c.PGClient.PingAndInit(nacos)
-}
-
-func (c *OperatorClient) CheckAndMakeHeal(nacos *nacosgroupv1alpha1.Nacos) {
+func (c *OperatorClient) PGEnsure(nacos *nacosgroupv1alpha1.Nacos) StepResult {
+	// 未配置 Postgres 则跳过
+	if nacos.Spec.Postgres.Host == "" {
+		return Continue()
+	}
+	// 若显式关闭初始化，直接跳过
+	if !nacos.Spec.PGInit.Enabled {
+		return Continue()
+	}
+	initialized := nacos.Status.PG.Initialized
+	if err := c.PGClient.PingAndInit(nacos); err != nil {
+		c.Recorder.Event(nacos, v1.EventTypeWarning, ReasonPGInitFailed, err.Error())
+		c.StatusClient.SetCondition(nacos, ConditionDatabaseReady, metav1.ConditionFalse, ReasonPGInitFailed, err.Error())
+		return ResultFromError(err)
+	}
+	if !initialized && nacos.Status.PG.Initialized {
+		c.Recorder.Eventf(nacos, v1.EventTypeNormal, ReasonPGInitialized, "postgres schema initialized, version %d", nacos.Status.PG.InitVersion)
+	}
+	c.StatusClient.SetCondition(nacos, ConditionDatabaseReady, metav1.ConditionTrue, ReasonPGInitialized,
+		fmt.Sprintf("postgres schema version %d", nacos.Status.PG.InitVersion))
+	return Continue()
+}
+
+func (c *OperatorClient) CheckAndMakeHeal(nacos *nacosgroupv1alpha1.Nacos) StepResult {
 	// 检查kind
-	pods := c.CheckClient.CheckKind(nacos)
+	pods, err := c.CheckClient.CheckKind(nacos)
+	if err != nil {
+		if toErr(err).Code == myErrors.CODE_QUORUM_LOST {
+			if err := c.RecoveryClient.MarkQuorumLost(nacos, time.Now()); err != nil {
+				return ResultFromError(err)
+			}
+			c.syncRecoverable(nacos)
+		}
+		c.StatusClient.SetCondition(nacos, ConditionAvailable, metav1.ConditionFalse, "InsufficientReadyPods", err.Error())
+		return ResultFromError(err)
+	}
+	c.StatusClient.SetCondition(nacos, ConditionAvailable, metav1.ConditionTrue, "MinimumReplicasReady",
+		fmt.Sprintf("%d/%d pods ready", len(pods), clusterReplicas(nacos)))
 	// 检查nacos
-	c.CheckClient.CheckNacos(nacos, pods)
+	err = c.CheckClient.CheckNacos(nacos, pods)
+	if len(nacos.Status.Partitions) > 0 {
+		c.Recorder.Event(nacos, v1.EventTypeWarning, ReasonSplitBrain, describePartitions(nacos.Status.Partitions))
+	}
+	c.checkRaftTopology(nacos)
+	if err != nil {
+		return ResultFromError(err)
+	}
+	// 灾难恢复中每次扩容一个成员，保存进度后等待新成员加入
+	grown, err := c.RecoveryClient.MarkHealthy(nacos)
+	if err != nil {
+		return Fail(err)
+	}
+	c.syncRecoverable(nacos)
+	if grown {
+		if err := c.StatusClient.UpdateStatus(nacos); err != nil {
+			return Fail(err)
+		}
+		return Requeue(recoveryRequeueInterval)
+	}
+	// 分批升级中成员以新版本重新加入后降低 partition，保存进度后等待下一个成员
+	upgraded, err := c.UpgradeClient.MarkHealthy(nacos, pods, time.Now())
+	if err != nil {
+		return Fail(err)
+	}
+	if upgraded {
+		if err := c.StatusClient.UpdateStatus(nacos); err != nil {
+			return Fail(err)
+		}
+		return Requeue(upgradeRequeueInterval)
+	}
+	c.checkCanary(nacos, pods)
+	return Continue()
+}
+
+// checkCanary 执行到期的金丝雀检查，失败时只记录条件与事件
+func (c *OperatorClient) checkCanary(nacos *nacosgroupv1alpha1.Nacos, pods []v1.Pod) {
+	if !c.CheckClient.CheckCanary(nacos, pods, time.Now()) {
+		return
+	}
+	canary := nacos.Status.Canary
+	failed := []string{}
+	if !canary.Config.Success {
+		failed = append(failed, fmt.Sprintf("%s: %s", CanaryConfig, canary.Config.Message))
+	}
+	if !canary.Naming.Success {
+		failed = append(failed, fmt.Sprintf("%s: %s", CanaryNaming, canary.Naming.Message))
+	}
+	if len(failed) == 0 {
+		c.StatusClient.SetCondition(nacos, ConditionCanaryPassed, metav1.ConditionTrue, ReasonCanaryPassed,
+			fmt.Sprintf("config %dms, naming %dms on %s", canary.Config.LatencyMilliseconds, canary.Naming.LatencyMilliseconds, canary.PodName))
+		return
+	}
+	message := strings.Join(failed, "; ")
+	c.StatusClient.SetCondition(nacos, ConditionCanaryPassed, metav1.ConditionFalse, ReasonCanaryFailed, message)
+	c.Recorder.Event(nacos, v1.EventTypeWarning, ReasonCanaryFailed, message)
+}
+
+// Recover 确认后开始以存活成员重建集群
+func (c *OperatorClient) Recover(nacos *nacosgroupv1alpha1.Nacos) StepResult {
+	return ResultFromError(c.RecoveryClient.Recover(nacos))
 }
 
-func (c *OperatorClient) UpdateStatus(nacos *nacosgroupv1alpha1.Nacos) {
-    c.StatusClient.UpdateStatusRunning(nacos)
+// Upgrade 检测镜像变化，开始、暂停或继续分批升级
+func (c *OperatorClient) Upgrade(nacos *nacosgroupv1alpha1.Nacos) StepResult {
+	return ResultFromError(c.UpgradeClient.Upgrade(nacos, time.Now()))
+}
+
+// ScaleDown 缩容过程中 Phase 为 Scaling，离开的成员退出集群、Pod 删除后才继续后续步骤
+func (c *OperatorClient) ScaleDown(nacos *nacosgroupv1alpha1.Nacos) StepResult {
+	pending, err := c.ScaleClient.ScaleDown(nacos, time.Now())
+	if err != nil {
+		return ResultFromError(err)
+	}
+	if !pending {
+		return Continue()
+	}
+	message := scaleMessage(nacos)
+	c.StatusClient.setPhase(nacos, nacosgroupv1alpha1.PhaseScale, message)
+	c.StatusClient.SetCondition(nacos, ConditionProgressing, metav1.ConditionTrue, "ScalingDown", message)
+	if err := c.StatusClient.UpdateStatus(nacos); err != nil {
+		return Fail(err)
+	}
+	return Requeue(scaleRequeueInterval)
+}
+
+// checkRaftTopology 根据 status.members 检测 Raft group 的不一致，只记录不中断调谐
+func (c *OperatorClient) checkRaftTopology(nacos *nacosgroupv1alpha1.Nacos) {
+	if len(nacos.Status.Members) == 0 {
+		return
+	}
+	issues := raftIssues(nacos.Status.Members)
+	if len(issues) == 0 {
+		c.StatusClient.SetCondition(nacos, ConditionRaftConsistent, metav1.ConditionTrue, "Consistent", "")
+		return
+	}
+	message := strings.Join(issues, "; ")
+	// 只在条件变为 False 时记录事件，避免每次调谐重复记录
+	if !meta.IsStatusConditionFalse(nacos.Status.Conditions, ConditionRaftConsistent) {
+		c.Recorder.Event(nacos, v1.EventTypeWarning, ReasonRaftInconsistent, message)
+	}
+	c.StatusClient.SetCondition(nacos, ConditionRaftConsistent, metav1.ConditionFalse, ReasonRaftInconsistent, message)
+}
+
+// syncRecoverable 灾难恢复等待确认时记录是否有运行中的成员可用于重建，恢复结束后移除
+func (c *OperatorClient) syncRecoverable(nacos *nacosgroupv1alpha1.Nacos) {
+	recovery := nacos.Status.Recovery
+	switch recovery.Phase {
+	case "":
+		meta.RemoveStatusCondition(&nacos.Status.Conditions, ConditionRecoverable)
+	case RecoveryAwaitingConfirmation:
+		if recovery.Survivor == "" {
+			c.StatusClient.SetCondition(nacos, ConditionRecoverable, metav1.ConditionFalse, "NoRunningMember",
+				"quorum lost and no member is running to rebuild the cluster from")
+			return
+		}
+		c.StatusClient.SetCondition(nacos, ConditionRecoverable, metav1.ConditionTrue, "SurvivorAvailable",
+			fmt.Sprintf("annotate %s with one of %s to rebuild the cluster", RecoveryConfirmAnnotation, strings.Join(recovery.Candidates, ", ")))
+	}
+}
+
+func (c *OperatorClient) UpdateStatus(nacos *nacosgroupv1alpha1.Nacos) StepResult {
+	if err := c.StatusClient.UpdateStatusRunning(nacos); err != nil {
+		return Fail(err)
+	}
+	// 开启金丝雀检查时定期重新调谐
+	if nacos.Spec.Canary.Enabled {
+		if wait := canaryWait(nacos, time.Now()); wait > 0 {
+			return Requeue(wait)
+		}
+		return Requeue(canaryInterval(nacos))
+	}
+	return Continue()
 }
 
 // RotateAdmin: rotate admin password via direct DB if needed
-func (c *OperatorClient) RotateAdmin(nacos *nacosgroupv1alpha1.Nacos) {
-    if c.PGClient == nil {
-        return
-    }
-    if nacos.Spec.Postgres.Host == "" {
-        return
-    }
-    if nacos.Spec.AdminCredentialsSecretRef.Name == "" {
-        return
-    }
-    c.PGClient.RotateAdminPassword(nacos)
+func (c *OperatorClient) RotateAdmin(nacos *nacosgroupv1alpha1.Nacos) StepResult {
+	if c.PGClient == nil {
+		return Continue()
+	}
+	if nacos.Spec.Postgres.Host == "" {
+		return Continue()
+	}
+	if nacos.Spec.AdminCredentialsSecretRef.Name == "" {
+		return Continue()
+	}
+	lastRotateTime := nacos.Status.Admin.LastRotateTime
+	if err := c.PGClient.RotateAdminPassword(nacos); err != nil {
+		c.Recorder.Event(nacos, v1.EventTypeWarning, ReasonAdminRotateFailed, err.Error())
+		c.StatusClient.SetCondition(nacos, ConditionAdminRotated, metav1.ConditionFalse, ReasonAdminRotateFailed, err.Error())
+		return ResultFromError(err)
+	}
+	if !nacos.Status.Admin.LastRotateTime.Equal(&lastRotateTime) {
+		c.Recorder.Event(nacos, v1.EventTypeNormal, ReasonAdminRotated, "admin password rotated")
+	}
+	c.StatusClient.SetCondition(nacos, ConditionAdminRotated, metav1.ConditionTrue, ReasonAdminRotated,
+		fmt.Sprintf("last rotated at %s", nacos.Status.Admin.LastRotateTime.UTC().Format(time.RFC3339)))
+	return Continue()
+}
+
+// configSyncedMessage 描述当前生效的配置
+func configSyncedMessage(nacos *nacosgroupv1alpha1.Nacos) string {
+	if nacos.Status.ConfigDigest == "" {
+		return ""
+	}
+	return "config digest " + nacos.Status.ConfigDigest
+}
+
+// finalizeRequeueInterval 删除 CR 时等待 StatefulSet 逐个缩容的间隔
+const finalizeRequeueInterval = time.Second * 2
+
+// Finalize 删除 CR 前的清理：按序缩容 StatefulSet，按 keepAfterDeletion 删除或保留 PVC，按需删除 PG schema。
+// 返回 ActionContinue 时表示清理完成，message 为清理结果描述
+func (c *OperatorClient) Finalize(nacos *nacosgroupv1alpha1.Nacos) (string, StepResult) {
+	done, err := c.KindClient.ScaleDownForDeletion(nacos)
+	if err != nil {
+		return "", Fail(err)
+	}
+	if !done {
+		return "", Requeue(finalizeRequeueInterval)
+	}
+
+	deleteMetrics(nacos)
+	messages := []string{"statefulset scaled down"}
+	if nacos.Spec.Volume.KeepAfterDeletion {
+		messages = append(messages, "pvc retained")
+	} else {
+		deleted, err := c.KindClient.DeleteVolumeClaims(nacos)
+		if err != nil {
+			return "", Fail(err)
+		}
+		messages = append(messages, fmt.Sprintf("%d pvc deleted", deleted))
+	}
+
+	if nacos.Spec.PGInit.DropOnDeletion && nacos.Status.PG.Initialized && nacos.Spec.Postgres.Host != "" {
+		if err := c.PGClient.DropSchema(nacos); err != nil {
+			return "", Fail(err)
+		}
+		messages = append(messages, "postgres schema dropped")
+	}
+	return strings.Join(messages, ", "), Continue()
 }
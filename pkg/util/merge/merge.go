@@ -152,14 +152,9 @@ func Probe(original, override *corev1.Probe) *corev1.Probe {
 		return override
 	}
 	merged := *original
-	if override.Handler.Exec != nil {
-		merged.Handler.Exec = override.Handler.Exec
-	}
-	if override.Handler.HTTPGet != nil {
-		merged.Handler.HTTPGet = override.Handler.HTTPGet
-	}
-	if override.Handler.TCPSocket != nil {
-		merged.Handler.TCPSocket = override.Handler.TCPSocket
+	// a probe allows only one handler, so an overriding handler replaces the original one
+	if override.Handler.Exec != nil || override.Handler.HTTPGet != nil || override.Handler.TCPSocket != nil {
+		merged.Handler = override.Handler
 	}
 	if override.InitialDelaySeconds != 0 {
 		merged.InitialDelaySeconds = override.InitialDelaySeconds
@@ -1,6 +1,8 @@
 package merge
 
 import (
+	"sort"
+
 	corev1 "k8s.io/api/core/v1"
 )
 
@@ -28,9 +30,9 @@ func EphemeralContainers(defaultContainers, overrideContainers []corev1.Ephemera
 		mergedContainers = append(mergedContainers, v)
 	}
 
-	//sort.SliceStable(mergedContainers, func(i, j int) bool {
-	//	return mergedContainers[i].Name < mergedContainers[j].Name
-	//})
+	sort.SliceStable(mergedContainers, func(i, j int) bool {
+		return mergedContainers[i].Name < mergedContainers[j].Name
+	})
 	return mergedContainers
 
 }
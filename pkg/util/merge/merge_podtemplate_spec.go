@@ -160,6 +160,10 @@ func TopologySpreadConstraints(original, override []corev1.TopologySpreadConstra
 	for _, v := range mergedMap {
 		mergedElements = append(mergedElements, v)
 	}
+	// keep the result stable, the statefulset is updated whenever the rendered template changes
+	sort.SliceStable(mergedElements, func(i, j int) bool {
+		return mergedElements[i].TopologyKey < mergedElements[j].TopologyKey
+	})
 	return mergedElements
 }
 
@@ -8,11 +8,14 @@ import (
 
 	appv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	nacosgroupv1alpha1 "nacos.io/nacos-operator/api/v1alpha1"
+	"nacos.io/nacos-operator/pkg/service/operator"
 	"nacos.io/nacos-operator/test/testutil"
 )
 
@@ -137,6 +140,14 @@ func TestStandaloneNacosCreation(t *testing.T) {
 		t.Errorf("Expected final phase Running, got %s", updatedNacos.Status.Phase)
 	}
 
+	// Verify phase transitions were recorded as Kubernetes Events
+	events := drainEvents(reconciler)
+	for _, reason := range []string{operator.ReasonCreating, operator.ReasonRunning} {
+		if !hasEvent(events, corev1.EventTypeNormal, reason) {
+			t.Errorf("Expected %s event, got %v", reason, events)
+		}
+	}
+
 	t.Log("✓✓✓ Standalone Nacos creation test PASSED ✓✓✓")
 }
 
@@ -257,7 +268,7 @@ func TestClusterNacosCreation(t *testing.T) {
 			t.Fatalf("Failed to get Nacos CR: %v", err)
 		}
 
-		t.Logf("Round %d: Phase=%s, Conditions=%d, Events=%d", i, updatedNacos.Status.Phase, len(updatedNacos.Status.Conditions), len(updatedNacos.Status.Event))
+		t.Logf("Round %d: Phase=%s, Instances=%d, Events=%d", i, updatedNacos.Status.Phase, len(updatedNacos.Status.Instances), len(updatedNacos.Status.Event))
 
 		// Print events for debugging
 		if len(updatedNacos.Status.Event) > 0 {
@@ -288,15 +299,15 @@ func TestClusterNacosCreation(t *testing.T) {
 		t.Errorf("Expected final phase Running, got %s", updatedNacos.Status.Phase)
 	}
 
-	// Verify all nodes are in conditions
-	if len(updatedNacos.Status.Conditions) != int(replicas) {
-		t.Errorf("Expected %d conditions (one per replica), got %d", replicas, len(updatedNacos.Status.Conditions))
+	// Verify all nodes are in instances
+	if len(updatedNacos.Status.Instances) != int(replicas) {
+		t.Errorf("Expected %d instances (one per replica), got %d", replicas, len(updatedNacos.Status.Instances))
 	} else {
-		t.Logf("✓ All %d nodes reported in conditions", replicas)
+		t.Logf("✓ All %d nodes reported in instances", replicas)
 		// Verify leader election
 		leaderCount := 0
 		followerCount := 0
-		for _, cond := range updatedNacos.Status.Conditions {
+		for _, cond := range updatedNacos.Status.Instances {
 			if cond.Type == "leader" {
 				leaderCount++
 				t.Logf("  - Leader: %s (IP: %s)", cond.PodName, cond.Instance)
@@ -313,5 +324,27 @@ func TestClusterNacosCreation(t *testing.T) {
 		}
 	}
 
+	// Verify standard conditions for kubectl wait / GitOps health checks
+	for condType, want := range map[string]metav1.ConditionStatus{
+		operator.ConditionReady:        metav1.ConditionTrue,
+		operator.ConditionAvailable:    metav1.ConditionTrue,
+		operator.ConditionProgressing:  metav1.ConditionFalse,
+		operator.ConditionDegraded:     metav1.ConditionFalse,
+		operator.ConditionConfigSynced: metav1.ConditionTrue,
+	} {
+		cond := meta.FindStatusCondition(updatedNacos.Status.Conditions, condType)
+		if cond == nil {
+			t.Errorf("Expected condition %s to be set", condType)
+		} else if cond.Status != want {
+			t.Errorf("Expected condition %s=%s, got %s (%s)", condType, want, cond.Status, cond.Message)
+		}
+	}
+	if len(updatedNacos.Status.Members) != int(replicas) {
+		t.Errorf("Expected %d members in status, got %d", replicas, len(updatedNacos.Status.Members))
+	}
+	if updatedNacos.Status.ObservedGeneration != updatedNacos.Generation {
+		t.Errorf("Expected observedGeneration %d, got %d", updatedNacos.Generation, updatedNacos.Status.ObservedGeneration)
+	}
+
 	t.Log("✓✓✓ Cluster Nacos creation test PASSED ✓✓✓")
 }
@@ -116,7 +116,7 @@ func TestClusterNacosScaling(t *testing.T) {
 			t.Fatalf("Failed to get Nacos CR: %v", err)
 		}
 
-		t.Logf("Round %d: Phase=%s, Conditions=%d", i, updatedNacos.Status.Phase, len(updatedNacos.Status.Conditions))
+		t.Logf("Round %d: Phase=%s, Instances=%d", i, updatedNacos.Status.Phase, len(updatedNacos.Status.Instances))
 
 		if updatedNacos.Status.Phase == nacosgroupv1alpha1.PhaseRunning {
 			t.Logf("✓ Status reached Running after scaling at round %d", i)
@@ -145,15 +145,15 @@ func TestClusterNacosScaling(t *testing.T) {
 		t.Errorf("Expected final replicas %d, got %d", newReplicas, *updatedNacos.Spec.Replicas)
 	}
 
-	// Verify all nodes are in conditions
-	if len(updatedNacos.Status.Conditions) != int(newReplicas) {
-		t.Errorf("Expected %d conditions after scaling, got %d", newReplicas, len(updatedNacos.Status.Conditions))
+	// Verify all nodes are in instances
+	if len(updatedNacos.Status.Instances) != int(newReplicas) {
+		t.Errorf("Expected %d instances after scaling, got %d", newReplicas, len(updatedNacos.Status.Instances))
 	} else {
-		t.Logf("✓ All %d nodes reported in conditions after scaling", newReplicas)
+		t.Logf("✓ All %d nodes reported in instances after scaling", newReplicas)
 		// Count leader and followers
 		leaderCount := 0
 		followerCount := 0
-		for _, cond := range updatedNacos.Status.Conditions {
+		for _, cond := range updatedNacos.Status.Instances {
 			if cond.Type == "leader" {
 				leaderCount++
 			} else if cond.Type == "follower" {
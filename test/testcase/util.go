@@ -1,6 +1,7 @@
 package testcase
 
 import (
+	"strings"
 	"testing"
 
 	appv1 "k8s.io/api/apps/v1"
@@ -8,6 +9,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	kubefake "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -25,6 +27,26 @@ func setupTest(t *testing.T, replicas int) (*controllers.NacosReconciler, client
 	return reconciler, fakeClient, mockServer, simulator, bridge
 }
 
+// drainEvents returns the Kubernetes Events recorded so far by the reconciler's fake recorder
+func drainEvents(reconciler *controllers.NacosReconciler) []string {
+	recorder := reconciler.Recorder.(*record.FakeRecorder)
+	var events []string
+	for len(recorder.Events) > 0 {
+		events = append(events, <-recorder.Events)
+	}
+	return events
+}
+
+// hasEvent reports whether an event with the given type and reason was recorded
+func hasEvent(events []string, eventType, reason string) bool {
+	for _, event := range events {
+		if strings.HasPrefix(event, eventType+" "+reason+" ") {
+			return true
+		}
+	}
+	return false
+}
+
 // setupTestWithName creates a test environment with custom StatefulSet name for mock server
 // Returns both mockServer (random port) and mockServer8848 (port 8848) for dynamic updates
 func setupTestWithName(t *testing.T, replicas int, stsName string) (*controllers.NacosReconciler, client.Client, *testutil.MockNacosServer, *testutil.MockNacosServer8848, *testutil.K8sSimulator, *testutil.ClientBridge) {
@@ -56,7 +78,8 @@ func setupTestWithName(t *testing.T, replicas int, stsName string) (*controllers
 	fakeKubeClient := kubefake.NewSimpleClientset()
 
 	// Create operator client
-	operatorClient := operator.NewOperatorClient(logger, fakeKubeClient, testScheme, fakeClient)
+	recorder := record.NewFakeRecorder(100)
+	operatorClient := operator.NewOperatorClient(logger, fakeKubeClient, nil, testScheme, fakeClient, recorder)
 
 	// Create reconciler
 	reconciler := &controllers.NacosReconciler{
@@ -64,6 +87,7 @@ func setupTestWithName(t *testing.T, replicas int, stsName string) (*controllers
 		Log:            logger,
 		Scheme:         testScheme,
 		OperaterClient: operatorClient,
+		Recorder:       recorder,
 	}
 
 	// Create simulator and bridge